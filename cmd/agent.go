@@ -4,6 +4,7 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -14,7 +15,10 @@ import (
 	"github.com/yosebyte/miniclaw/internal/provider"
 )
 
-var agentMessage string
+var (
+	agentMessage  string
+	agentProgress bool
+)
 
 var agentCmd = &cobra.Command{
 	Use:   "agent",
@@ -30,7 +34,18 @@ var agentCmd = &cobra.Command{
 
 		claude := provider.New(cfg)
 		loop := agent.NewLoop(cfg, claude)
-		ctx := context.Background()
+		if path := cfg.AuditLogPath(); path != "" {
+			loop.Subscribe(agent.NewAuditLog(path))
+		}
+		if agentProgress {
+			loop.Subscribe(agent.ObserverFunc(func(e agent.Event) {
+				if e.Kind == agent.EventToolFinished {
+					fmt.Fprintln(os.Stderr, "  "+agent.FormatStatus(e))
+				}
+			}))
+		}
+		defer loop.Close()
+		ctx := agent.WithOrigin(context.Background(), agent.Origin{Source: "cli", UserName: os.Getenv("USER")})
 
 		if agentMessage != "" {
 			return runOnce(ctx, loop, agentMessage)
@@ -41,6 +56,7 @@ var agentCmd = &cobra.Command{
 
 func init() {
 	agentCmd.Flags().StringVarP(&agentMessage, "message", "m", "", "Single message to send")
+	agentCmd.Flags().BoolVarP(&agentProgress, "progress", "p", false, "Print a status line for each tool call")
 }
 
 func runOnce(ctx context.Context, loop *agent.Loop, msg string) error {
@@ -52,7 +68,19 @@ func runOnce(ctx context.Context, loop *agent.Loop, msg string) error {
 	return nil
 }
 
+// errExit is returned by the /exit command to end the interactive session.
+var errExit = errors.New("exit")
+
 func runInteractive(ctx context.Context, loop *agent.Loop) error {
+	loop.Commands().Register(agent.Command{
+		Name:        "exit",
+		Aliases:     []string{"quit"},
+		Description: "Leave interactive mode",
+		Handler: func(context.Context, agent.CommandRequest) (string, error) {
+			return "", errExit
+		},
+	})
+
 	fmt.Println("🐾 miniclaw interactive mode. Type /help for commands, exit/quit to leave.")
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
@@ -64,13 +92,16 @@ func runInteractive(ctx context.Context, loop *agent.Loop) error {
 		if line == "" {
 			continue
 		}
-		lower := strings.ToLower(line)
-		if lower == "exit" || lower == "quit" || lower == "/exit" || lower == "/quit" || lower == ":q" {
-			fmt.Println("Goodbye!")
-			break
+		// Accept bare exit words ("quit", ":q") as their slash-command form.
+		if c, ok := loop.Commands().Lookup(strings.TrimPrefix(line, ":")); ok && c.Name == "exit" {
+			line = "/" + c.Name
 		}
 
 		resp, err := loop.ProcessMessage(ctx, "cli:direct", "", line)
+		if errors.Is(err, errExit) {
+			fmt.Println("Goodbye!")
+			break
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			continue
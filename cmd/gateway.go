@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yosebyte/miniclaw/internal/agent"
@@ -33,10 +34,19 @@ var gatewayCmd = &cobra.Command{
 			return fmt.Errorf("telegram.token not configured in %s", config.ConfigPath())
 		}
 
+		release, err := acquireGatewayLock(cfg, "the gateway")
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		claude := provider.New(cfg)
 
 		// 1. Create the agent loop (base tools only).
 		loop := agent.NewLoop(cfg, claude)
+		if path := cfg.AuditLogPath(); path != "" {
+			loop.Subscribe(agent.NewAuditLog(path))
+		}
 
 		// 2. Create the Telegram bot — provides the Send function.
 		bot := telegram.New(cfg, loop)
@@ -49,6 +59,7 @@ var gatewayCmd = &cobra.Command{
 			config.CronPath(),
 			bot.Send,
 			func(ctx context.Context, chatID, message string) (string, error) {
+				ctx = agent.WithOrigin(ctx, agent.Origin{Source: "cron"})
 				return loop.ProcessMessage(ctx, "cron_"+chatID, chatID, message)
 			},
 		)
@@ -60,6 +71,7 @@ var gatewayCmd = &cobra.Command{
 		var hbService *heartbeat.Service
 		if cfg.Heartbeat.Enabled {
 			hbService = heartbeat.New(cfg, func(ctx context.Context, sessionKey, chatID, message string) (string, error) {
+				ctx = agent.WithOrigin(ctx, agent.Origin{Source: "heartbeat"})
 				return loop.ProcessMessage(ctx, sessionKey, chatID, message)
 			}, bot.Send)
 		}
@@ -73,6 +85,11 @@ var gatewayCmd = &cobra.Command{
 		if hbService != nil {
 			go hbService.Run(ctx)
 		}
+		go loop.PromptCommands().Watch(ctx, 10*time.Second)
+		go loop.RunJournalRollups(ctx, time.Hour)
+		go loop.RunIdleSweeper(ctx, 10*time.Minute)
+
+		defer loop.Close()
 
 		slog.Info("miniclaw gateway starting")
 		return bot.Run(ctx)
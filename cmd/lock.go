@@ -0,0 +1,44 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yosebyte/miniclaw/internal/config"
+)
+
+// errLocked is returned by lockFile when another process holds the lock.
+var errLocked = errors.New("locked")
+
+// acquireGatewayLock takes the lock a running gateway holds on its sessions
+// and memory. The gateway keeps it while it runs; commands that rewrite the
+// same files take it briefly, so neither overwrites the other's changes from
+// a stale cache. what names the holder in the other side's error message.
+func acquireGatewayLock(cfg *config.Config, what string) (release func(), err error) {
+	path := cfg.GatewayLockPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		holder, _ := os.ReadFile(path)
+		f.Close()
+		if errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("%s is using %s; stop it first", strings.TrimSpace(string(holder)), filepath.Dir(path))
+		}
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	f.Truncate(0)
+	fmt.Fprintf(f, "%s (pid %d)\n", what, os.Getpid())
+	return func() {
+		f.Truncate(0)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,13 @@
+// MIT License - Copyright (c) 2026 yosebyte
+
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// lockFile is a no-op where flock is unavailable; the gateway lock is only
+// enforced on Unix.
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,330 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yosebyte/miniclaw/internal/agent"
+	"github.com/yosebyte/miniclaw/internal/config"
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+var memoryWorkspace string
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Inspect, edit and maintain long-term memory (MEMORY.md)",
+}
+
+var memoryShowEntries bool
+
+var memoryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print MEMORY.md",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		entries, err := mem.Entries("")
+		if err != nil {
+			return err
+		}
+		if memoryShowEntries {
+			fmt.Println(agent.FormatMemoryEntries(entries, cfg.Location()))
+			return nil
+		}
+		if len(entries) == 0 {
+			fmt.Println("Memory is empty.")
+			return nil
+		}
+		fmt.Print(mem.ReadMemory())
+		return nil
+	},
+}
+
+var memoryEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit MEMORY.md in $EDITOR",
+	Long: `Opens MEMORY.md in $EDITOR (or vi). Keep the "(#id)" tag on facts you change so
+they keep their history; new lines become new entries and deleted lines are forgotten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		// The whole file is replaced, so the gateway must not add facts meanwhile.
+		release, err := acquireGatewayLock(cfg, "miniclaw memory edit")
+		if err != nil {
+			return err
+		}
+		defer release()
+		if _, err := mem.Entries(""); err != nil { // pick up earlier hand edits first
+			return err
+		}
+		before := mem.ReadMemory()
+		f, err := os.CreateTemp("", "MEMORY-*.md")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(before)
+		f.Close()
+		if err != nil {
+			return err
+		}
+
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			editor = "vi"
+		}
+		ed := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+		ed.Stdin, ed.Stdout, ed.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := ed.Run(); err != nil {
+			return fmt.Errorf("editor: %w", err)
+		}
+		after, err := os.ReadFile(f.Name())
+		if err != nil {
+			return err
+		}
+		changed, err := mem.WriteMemory(string(after), "cli")
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Println("No changes.")
+			return nil
+		}
+		revs, _ := mem.Revisions()
+		if len(revs) > 0 {
+			r := revs[len(revs)-1]
+			fmt.Printf("✅ Memory saved as r%d (+%d -%d lines).\n", r.Rev, r.Added, r.Removed)
+		}
+		return nil
+	},
+}
+
+var memoryConsolidateSession string
+
+var memoryConsolidateCmd = &cobra.Command{
+	Use:     "consolidate",
+	Short:   "Consolidate a session's unconsolidated messages into memory now",
+	Example: `  miniclaw memory consolidate --session telegram_123456`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if memoryConsolidateSession == "" {
+			return fmt.Errorf("--session is required (see: miniclaw sessions list)")
+		}
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		if !cfg.IsAuthenticated() {
+			return fmt.Errorf("not authenticated; run: miniclaw provider login")
+		}
+		// A running gateway caches the session and would overwrite the result.
+		release, err := acquireGatewayLock(cfg, "miniclaw memory consolidate")
+		if err != nil {
+			return err
+		}
+		defer release()
+		s, err := findSession(sm, memoryConsolidateSession)
+		if err != nil {
+			return err
+		}
+		ws, _ := cfg.ResolveTenant(s.ChatID, s.UserID)
+		if memoryWorkspace != "" {
+			ws = memoryWorkspace
+		}
+		mem := agent.NewMemoryStore(ws)
+		mem.SetLocation(cfg.Location())
+
+		res, err := mem.Consolidate(cmd.Context(), provider.New(cfg), s, 0)
+		if err != nil {
+			return err
+		}
+		sm.Update(s.Key, func(cur *agent.Session) {
+			if res.End > cur.LastConsolidated && res.End <= len(cur.Messages) {
+				cur.LastConsolidated = res.End
+			}
+		})
+		if err := sm.Flush(); err != nil {
+			return err
+		}
+		switch {
+		case res.End == res.Start:
+			fmt.Println("Nothing to consolidate.")
+		case res.Queued:
+			fmt.Printf("⚠️ Consolidation of messages %d–%d failed after %d attempts; queued for the next run.\n", res.Start, res.End, res.Attempts)
+		default:
+			fmt.Printf("✅ Consolidated messages %d–%d into %s (%d memory changes).\n%s\n", res.Start, res.End, ws, res.Applied, res.HistoryEntry)
+		}
+		return nil
+	},
+}
+
+var memoryCompactForce bool
+
+var memoryCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Deduplicate and reorganize MEMORY.md when it exceeds provider.memoryMaxSize",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		if !cfg.IsAuthenticated() {
+			return fmt.Errorf("not authenticated; run: miniclaw provider login")
+		}
+		release, err := acquireGatewayLock(cfg, "miniclaw memory compact")
+		if err != nil {
+			return err
+		}
+		defer release()
+		res, err := mem.Compact(cmd.Context(), provider.New(cfg), cfg.Provider.MemoryMaxSize, memoryCompactForce)
+		if err != nil {
+			return err
+		}
+		if res.Skipped {
+			fmt.Printf("MEMORY.md is %d bytes, within the limit; nothing to do (use --force to compact anyway).\n", res.SizeBefore)
+			return nil
+		}
+		fmt.Printf("✅ Compacted MEMORY.md from %d to %d bytes (%d entries) as r%d.\n", res.SizeBefore, res.SizeAfter, res.EntriesAfter, res.Revision)
+		return nil
+	},
+}
+
+var memoryLogLast int
+
+var memoryLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "List memory revisions, newest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		revs, err := mem.Revisions()
+		if err != nil {
+			return err
+		}
+		if memoryLogLast > 0 && len(revs) > memoryLogLast {
+			revs = revs[len(revs)-memoryLogLast:]
+		}
+		fmt.Println(agent.FormatRevisions(revs, cfg.Location()))
+		return nil
+	},
+}
+
+var memoryDiffCmd = &cobra.Command{
+	Use:   "diff [rev] [rev]",
+	Short: "Show how MEMORY.md changed",
+	Long: `With no arguments, shows the latest change. With one revision, shows the change
+made by that revision. With two, compares them.`,
+	Example: `  miniclaw memory diff
+  miniclaw memory diff r12
+  miniclaw memory diff 3 12`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		var from, to int
+		switch len(args) {
+		case 0:
+			revs, err := mem.Revisions()
+			if err != nil {
+				return err
+			}
+			if len(revs) == 0 {
+				fmt.Println("No memory changes recorded yet.")
+				return nil
+			}
+			to = revs[len(revs)-1].Rev
+			from = to - 1
+		case 1:
+			if to, err = parseRev(args[0]); err != nil {
+				return err
+			}
+			from = to - 1
+		default:
+			if from, err = parseRev(args[0]); err != nil {
+				return err
+			}
+			if to, err = parseRev(args[1]); err != nil {
+				return err
+			}
+		}
+		diff, err := mem.Diff(from, to)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("r%d → r%d\n%s\n", from, to, diff)
+		return nil
+	},
+}
+
+var memoryRollbackCmd = &cobra.Command{
+	Use:   "rollback <rev>",
+	Short: "Restore memory to a revision (recorded as a new revision)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rev, err := parseRev(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, mem, err := loadMemory()
+		if err != nil {
+			return err
+		}
+		release, err := acquireGatewayLock(cfg, "miniclaw memory rollback")
+		if err != nil {
+			return err
+		}
+		defer release()
+		r, err := mem.Rollback(rev, "cli")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Memory restored to r%d as r%d (+%d -%d lines).\n", rev, r.Rev, r.Added, r.Removed)
+		return nil
+	},
+}
+
+func init() {
+	memoryCmd.PersistentFlags().StringVarP(&memoryWorkspace, "workspace", "w", "", "Workspace to use (default: the configured workspace)")
+	memoryShowCmd.Flags().BoolVar(&memoryShowEntries, "entries", false, "List entries with category, last update and session")
+	memoryConsolidateCmd.Flags().StringVarP(&memoryConsolidateSession, "session", "s", "", "Session key (see: miniclaw sessions list)")
+	memoryCompactCmd.Flags().BoolVarP(&memoryCompactForce, "force", "f", false, "Compact even when under the size limit")
+	memoryLogCmd.Flags().IntVarP(&memoryLogLast, "last", "n", 20, "Only show the last N revisions (0 for all)")
+
+	memoryCmd.AddCommand(memoryShowCmd, memoryEditCmd, memoryConsolidateCmd, memoryCompactCmd, memoryLogCmd, memoryDiffCmd, memoryRollbackCmd)
+}
+
+func loadMemory() (*config.Config, *agent.MemoryStore, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	ws := cfg.WorkspacePath()
+	if memoryWorkspace != "" {
+		ws = memoryWorkspace
+	}
+	mem := agent.NewMemoryStore(ws)
+	mem.SetLocation(cfg.Location())
+	return cfg, mem, nil
+}
+
+// parseRev accepts "12" or "r12".
+func parseRev(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(s), "r"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid revision %q", s)
+	}
+	return n, nil
+}
@@ -29,4 +29,7 @@ func init() {
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(providerCmd)
 	rootCmd.AddCommand(cronCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(memoryCmd)
+	rootCmd.AddCommand(sandboxInitCmd)
 }
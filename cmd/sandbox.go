@@ -0,0 +1,22 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// sandboxInitCmd is started by the exec tool inside new namespaces; see tools.SandboxInit.
+var sandboxInitCmd = &cobra.Command{
+	Use:                tools.SandboxInitCommand + " <workspace> <dir> -- <command...>",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := tools.SandboxInit(args)
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		os.Exit(126)
+	},
+}
@@ -0,0 +1,282 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yosebyte/miniclaw/internal/agent"
+	"github.com/yosebyte/miniclaw/internal/config"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "List, show, export and clean up conversations",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List sessions with message counts and last activity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		infos, err := sm.List()
+		if err != nil {
+			return err
+		}
+		if len(infos) == 0 {
+			fmt.Println("No sessions.")
+			return nil
+		}
+		loc := cfg.Location()
+		legacy := 0
+		for _, s := range infos {
+			note := ""
+			if s.Legacy {
+				note = "   (old format)"
+				legacy++
+			}
+			fmt.Printf("%-40s %5d msgs   last %s%s\n", s.Key, s.Messages, s.LastActivity.In(loc).Format("2006-01-02 15:04"), note)
+		}
+		if legacy > 0 {
+			fmt.Printf("\n%d sessions use the old JSON format; convert them with: miniclaw sessions migrate\n", legacy)
+		}
+		return nil
+	},
+}
+
+var sessionsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convert sessions in the old JSON format to JSONL",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		release, err := acquireGatewayLock(cfg, "miniclaw sessions migrate")
+		if err != nil {
+			return err
+		}
+		defer release()
+		migrated, err := sm.MigrateLegacy()
+		for _, key := range migrated {
+			fmt.Printf("✅ Session %s migrated (the original is kept as .json.bak).\n", key)
+		}
+		if err != nil {
+			return err
+		}
+		if len(migrated) == 0 {
+			fmt.Println("No sessions to migrate.")
+		}
+		return nil
+	},
+}
+
+var sessionsShowLast int
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <key>",
+	Short: "Print a session transcript",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		s, err := findSession(sm, args[0])
+		if err != nil {
+			return err
+		}
+		if sessionsShowLast > 0 && len(s.Messages) > sessionsShowLast {
+			s.Messages = s.Messages[len(s.Messages)-sessionsShowLast:]
+		}
+		return agent.ExportSession(os.Stdout, s, "text", cfg.Location())
+	},
+}
+
+var (
+	sessionsExportFormat string
+	sessionsExportOutput string
+)
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <key>",
+	Short: "Export a session as Markdown, HTML or JSONL",
+	Example: `  miniclaw sessions export telegram_123456 --format html -o chat.html
+  miniclaw sessions export cli:direct --format jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		s, err := findSession(sm, args[0])
+		if err != nil {
+			return err
+		}
+		var w io.Writer = os.Stdout
+		if sessionsExportOutput != "" {
+			f, err := os.OpenFile(sessionsExportOutput, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := agent.ExportSession(w, s, sessionsExportFormat, cfg.Location()); err != nil {
+			return err
+		}
+		if sessionsExportOutput != "" {
+			fmt.Printf("✅ Exported %d messages to %s\n", len(s.Messages), sessionsExportOutput)
+		}
+		return nil
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:     "delete <key>...",
+	Aliases: []string{"rm"},
+	Short:   "Delete sessions",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		// The gateway's write-behind cache would write deleted sessions back.
+		release, err := acquireGatewayLock(cfg, "miniclaw sessions delete")
+		if err != nil {
+			return err
+		}
+		defer release()
+		for _, arg := range args {
+			s, err := findSession(sm, arg)
+			if err != nil {
+				return err
+			}
+			if err := sm.Delete(s.Key); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Session %s deleted.\n", s.Key)
+		}
+		return nil
+	},
+}
+
+var (
+	sessionsPruneOlderThan string
+	sessionsPruneDryRun    bool
+)
+
+var sessionsPruneCmd = &cobra.Command{
+	Use:     "prune",
+	Short:   "Delete sessions with no activity within a retention period",
+	Example: `  miniclaw sessions prune --older-than 90d --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		age, err := parseAge(sessionsPruneOlderThan)
+		if err != nil {
+			return err
+		}
+		cfg, sm, err := loadSessions()
+		if err != nil {
+			return err
+		}
+		if !sessionsPruneDryRun {
+			release, err := acquireGatewayLock(cfg, "miniclaw sessions prune")
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+		infos, err := sm.List()
+		if err != nil {
+			return err
+		}
+		cutoff := time.Now().Add(-age)
+		n := 0
+		for _, s := range infos {
+			if !s.LastActivity.Before(cutoff) {
+				continue
+			}
+			n++
+			if sessionsPruneDryRun {
+				fmt.Printf("would delete %s (last %s)\n", s.Key, s.LastActivity.Format("2006-01-02"))
+				continue
+			}
+			if err := sm.Delete(s.Key); err != nil {
+				return err
+			}
+			fmt.Printf("deleted %s (last %s)\n", s.Key, s.LastActivity.Format("2006-01-02"))
+		}
+		if n == 0 {
+			fmt.Printf("No sessions older than %s.\n", sessionsPruneOlderThan)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionsShowCmd.Flags().IntVarP(&sessionsShowLast, "last", "n", 0, "Only show the last N messages")
+	sessionsExportCmd.Flags().StringVarP(&sessionsExportFormat, "format", "f", "markdown", "Output format: "+strings.Join(agent.ExportFormats, ", "))
+	sessionsExportCmd.Flags().StringVarP(&sessionsExportOutput, "output", "o", "", "Write to file instead of stdout")
+	sessionsPruneCmd.Flags().StringVar(&sessionsPruneOlderThan, "older-than", "90d", "Retention period (e.g. 90d, 2w, 36h)")
+	sessionsPruneCmd.Flags().BoolVar(&sessionsPruneDryRun, "dry-run", false, "Only list what would be deleted")
+
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsExportCmd, sessionsDeleteCmd, sessionsPruneCmd, sessionsMigrateCmd)
+}
+
+func loadSessions() (*config.Config, *agent.SessionManager, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, agent.NewSessionManager(cfg.SessionsPath()), nil
+}
+
+// findSession accepts a session key or its file name (e.g. telegram_123.jsonl).
+func findSession(sm *agent.SessionManager, query string) (*agent.Session, error) {
+	infos, err := sm.List()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.TrimSuffix(strings.TrimSuffix(query, ".jsonl"), ".json")
+	for _, s := range infos {
+		if s.Key == query {
+			return sm.Peek(s.Key), nil
+		}
+	}
+	safe := strings.NewReplacer(":", "_", "/", "_", "\\", "_")
+	for _, s := range infos {
+		if safe.Replace(s.Key) == query {
+			return sm.Peek(s.Key), nil
+		}
+	}
+	return nil, fmt.Errorf("session %q not found (see: miniclaw sessions list)", query)
+}
+
+// parseAge parses durations with day and week units in addition to Go's.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
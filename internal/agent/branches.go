@@ -0,0 +1,178 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mainSession is the name of a chat's original, unnamed session.
+const mainSession = "main"
+
+// SessionIndex records the named sessions of one chat and which one is active.
+// The "main" session always exists implicitly and uses the chat's base key.
+type SessionIndex struct {
+	Active   string            `json:"active"`
+	Sessions map[string]string `json:"sessions"`           // name -> session key
+	Switched time.Time         `json:"switched,omitempty"` // when Active last changed
+}
+
+// SessionInfo summarises one named session for listings.
+type SessionInfo struct {
+	Name         string
+	Key          string
+	Active       bool
+	Messages     int
+	LastActivity time.Time
+	Legacy       bool // still in the pre-JSONL format; see MigrateLegacy
+}
+
+var reSessionName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+func (m *SessionManager) indexPath(baseKey string) string {
+	return filepath.Join(m.dir, "index", safeKey(baseKey)+".json")
+}
+
+// Index loads the named-session index for a chat.
+func (m *SessionManager) Index(baseKey string) SessionIndex {
+	idx := SessionIndex{Active: mainSession, Sessions: map[string]string{}}
+	if data, err := os.ReadFile(m.indexPath(baseKey)); err == nil {
+		_ = json.Unmarshal(data, &idx)
+	}
+	if idx.Sessions == nil {
+		idx.Sessions = map[string]string{}
+	}
+	idx.Sessions[mainSession] = baseKey
+	if _, ok := idx.Sessions[idx.Active]; !ok {
+		idx.Active = mainSession
+	}
+	return idx
+}
+
+func (m *SessionManager) saveIndex(baseKey string, idx SessionIndex) error {
+	path := m.indexPath(baseKey)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating session index dir: %w", err)
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// Resolve returns the key of the chat's active session.
+func (m *SessionManager) Resolve(baseKey string) string {
+	idx := m.Index(baseKey)
+	return idx.Sessions[idx.Active]
+}
+
+// Fork copies the active session under a new name and makes the copy active.
+func (m *SessionManager) Fork(baseKey, name string) error {
+	if !reSessionName.MatchString(name) {
+		return fmt.Errorf("invalid session name %q (use lowercase letters, digits, - and _)", name)
+	}
+	idx := m.Index(baseKey)
+	if _, exists := idx.Sessions[name]; exists {
+		return fmt.Errorf("session %q already exists; use /switch %s", name, name)
+	}
+	dst := m.Get(idx.Sessions[idx.Active])
+	dst.Key = baseKey + "#" + name
+	if err := m.Save(dst); err != nil {
+		return err
+	}
+	idx.Sessions[name] = dst.Key
+	idx.Active, idx.Switched = name, time.Now()
+	return m.saveIndex(baseKey, idx)
+}
+
+// Switch makes the named session active, starting an empty one if it does not exist.
+func (m *SessionManager) Switch(baseKey, name string) (created bool, err error) {
+	idx := m.Index(baseKey)
+	if _, ok := idx.Sessions[name]; !ok {
+		if !reSessionName.MatchString(name) {
+			return false, fmt.Errorf("invalid session name %q (use lowercase letters, digits, - and _)", name)
+		}
+		idx.Sessions[name] = baseKey + "#" + name
+		created = true
+	}
+	idx.Active, idx.Switched = name, time.Now()
+	return created, m.saveIndex(baseKey, idx)
+}
+
+// ListNamed returns the chat's named sessions sorted by name.
+func (m *SessionManager) ListNamed(baseKey string) []SessionInfo {
+	idx := m.Index(baseKey)
+	infos := make([]SessionInfo, 0, len(idx.Sessions))
+	for name, key := range idx.Sessions {
+		s := m.Peek(key)
+		info := SessionInfo{Name: name, Key: key, Active: name == idx.Active, Messages: len(s.Messages)}
+		if n := len(s.Messages); n > 0 {
+			info.LastActivity = s.Messages[n-1].Timestamp
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// registerSessionCommands adds /fork, /switch and /sessions.
+func (l *Loop) registerSessionCommands() {
+	l.cmds.Register(Command{
+		Name:        "fork",
+		Usage:       "<name>",
+		Description: "Branch the conversation into a new named session",
+		MinArgs:     1,
+		Handler: func(_ context.Context, req CommandRequest) (string, error) {
+			name := strings.ToLower(req.Args[0])
+			if err := l.sessions.Fork(req.BaseKey, name); err != nil {
+				return "⚠️ " + err.Error(), nil
+			}
+			return fmt.Sprintf("🌿 Forked into session %q. Use /switch %s to go back.", name, mainSession), nil
+		},
+	})
+	l.cmds.Register(Command{
+		Name:        "switch",
+		Usage:       "<name>",
+		Description: "Switch to a named session (created if new)",
+		MinArgs:     1,
+		Handler: func(_ context.Context, req CommandRequest) (string, error) {
+			name := strings.ToLower(req.Args[0])
+			created, err := l.sessions.Switch(req.BaseKey, name)
+			if err != nil {
+				return "⚠️ " + err.Error(), nil
+			}
+			if created {
+				return fmt.Sprintf("🆕 Started new session %q.", name), nil
+			}
+			return fmt.Sprintf("🔀 Switched to session %q.", name), nil
+		},
+	})
+	l.cmds.Register(Command{
+		Name:        "sessions",
+		Description: "List this chat's saved sessions",
+		Handler: func(_ context.Context, req CommandRequest) (string, error) {
+			var sb strings.Builder
+			sb.WriteString("🗂 Sessions:")
+			for _, s := range l.sessions.ListNamed(req.BaseKey) {
+				mark := "  "
+				if s.Active {
+					mark = "▶ "
+				}
+				last := "never"
+				if !s.LastActivity.IsZero() {
+					last = s.LastActivity.In(l.cfg.Location()).Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(&sb, "\n%s%s — %d messages, last %s", mark, s.Name, s.Messages, last)
+			}
+			return sb.String(), nil
+		},
+	})
+}
@@ -0,0 +1,285 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CommandHandler runs a slash command and returns the reply to show the user.
+type CommandHandler func(ctx context.Context, req CommandRequest) (string, error)
+
+// Command is a slash command handled without (or before) calling the model.
+type Command struct {
+	Name        string   // without the leading slash, e.g. "new"
+	Aliases     []string // alternative names, e.g. "quit" for "exit"
+	Usage       string   // argument hint shown in help, e.g. "<name>"
+	Description string
+	MinArgs     int
+	Hidden      bool // omitted from /help and the Telegram menu
+	Handler     CommandHandler
+}
+
+// CommandRequest carries a parsed command invocation.
+type CommandRequest struct {
+	BaseKey    string // the chat's session key before resolving named sessions
+	SessionKey string // the chat's active session
+	ChatID     string
+	Name       string   // canonical command name
+	Args       []string // whitespace-split arguments, quotes honoured
+	RawArgs    string   // everything after the command name, trimmed
+}
+
+// CommandRegistry holds slash commands shared by all channels.
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	cmds     map[string]*Command
+	aliases  map[string]string
+	onChange []func()
+}
+
+// NewCommandRegistry creates an empty CommandRegistry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		cmds:    make(map[string]*Command),
+		aliases: make(map[string]string),
+	}
+}
+
+// Register adds or replaces a command.
+func (r *CommandRegistry) Register(c Command) {
+	r.mu.Lock()
+	name := strings.ToLower(c.Name)
+	c.Name = name
+	r.cmds[name] = &c
+	for _, a := range c.Aliases {
+		r.aliases[strings.ToLower(a)] = name
+	}
+	r.mu.Unlock()
+	r.changed()
+}
+
+// Unregister removes a command and its aliases.
+func (r *CommandRegistry) Unregister(name string) {
+	r.mu.Lock()
+	name = strings.ToLower(name)
+	c, ok := r.cmds[name]
+	if ok {
+		delete(r.cmds, name)
+		for _, a := range c.Aliases {
+			delete(r.aliases, strings.ToLower(a))
+		}
+	}
+	r.mu.Unlock()
+	if ok {
+		r.changed()
+	}
+}
+
+// OnChange registers fn to be called whenever the set of commands changes.
+func (r *CommandRegistry) OnChange(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+func (r *CommandRegistry) changed() {
+	r.mu.RLock()
+	fns := append([]func(){}, r.onChange...)
+	r.mu.RUnlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Lookup finds a command by name or alias.
+func (r *CommandRegistry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name = strings.ToLower(name)
+	if c, ok := r.cmds[name]; ok {
+		return c, true
+	}
+	if canonical, ok := r.aliases[name]; ok {
+		c, ok := r.cmds[canonical]
+		return c, ok
+	}
+	return nil, false
+}
+
+// List returns the visible commands sorted by name.
+func (r *CommandRegistry) List() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Command, 0, len(r.cmds))
+	for _, c := range r.cmds {
+		if !c.Hidden {
+			list = append(list, *c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Help renders the command list for /help.
+func (r *CommandRegistry) Help() string {
+	var sb strings.Builder
+	sb.WriteString("🐾 miniclaw commands:")
+	for _, c := range r.List() {
+		sb.WriteString("\n/" + c.Name)
+		if c.Usage != "" {
+			sb.WriteString(" " + c.Usage)
+		}
+		sb.WriteString(" — " + c.Description)
+	}
+	return sb.String()
+}
+
+// Dispatch runs text as a command if it names a registered one.
+// handled is false when text is not a slash command or the command is unknown,
+// in which case the message should go to the model as usual.
+func (r *CommandRegistry) Dispatch(ctx context.Context, baseKey, sessionKey, chatID, text string) (reply string, handled bool, err error) {
+	name, rawArgs, ok := ParseCommand(text)
+	if !ok {
+		return "", false, nil
+	}
+	c, ok := r.Lookup(name)
+	if !ok {
+		return "", false, nil
+	}
+	args := SplitArgs(rawArgs)
+	if len(args) < c.MinArgs {
+		return fmt.Sprintf("Usage: /%s %s", c.Name, c.Usage), true, nil
+	}
+	reply, err = c.Handler(ctx, CommandRequest{
+		BaseKey:    baseKey,
+		SessionKey: sessionKey,
+		ChatID:     chatID,
+		Name:       c.Name,
+		Args:       args,
+		RawArgs:    rawArgs,
+	})
+	return reply, true, err
+}
+
+// ParseCommand splits "/name@bot args..." into its name and raw argument string.
+func ParseCommand(text string) (name, rawArgs string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") || len(text) < 2 {
+		return "", "", false
+	}
+	head, rest, _ := strings.Cut(text[1:], " ")
+	if i := strings.IndexAny(head, "\n\t"); i >= 0 {
+		rest = head[i+1:] + " " + rest
+		head = head[:i]
+	}
+	head, _, _ = strings.Cut(head, "@") // Telegram appends @botname in groups
+	if head == "" {
+		return "", "", false
+	}
+	return strings.ToLower(head), strings.TrimSpace(rest), true
+}
+
+// SplitArgs splits s on whitespace, keeping single- or double-quoted runs together.
+func SplitArgs(s string) []string {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+// quotePreview shortens a message for command replies.
+func quotePreview(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return "“" + truncateRunes(s, 60) + "”"
+}
+
+// registerBuiltinCommands adds the commands every channel supports.
+func (l *Loop) registerBuiltinCommands() {
+	l.cmds.Register(Command{
+		Name:        "new",
+		Description: "Start a new conversation",
+		Handler: func(ctx context.Context, req CommandRequest) (string, error) {
+			reply := "New session started. Memory consolidation in progress."
+			if n := l.resetSession(ctx, req.SessionKey, req.ChatID, true); n > 0 {
+				reply += fmt.Sprintf(" Stopped %d background process(es).", n)
+			}
+			return reply, nil
+		},
+	})
+	l.cmds.Register(Command{
+		Name:        "undo",
+		Description: "Remove the last exchange from the conversation",
+		Handler: func(_ context.Context, req CommandRequest) (string, error) {
+			var removed SessionMessage
+			var ok bool
+			l.sessions.Update(req.SessionKey, func(s *Session) {
+				removed, ok = s.RemoveLastTurn()
+			})
+			if !ok {
+				return "Nothing to undo.", nil
+			}
+			return "↩️ Removed the last exchange: " + quotePreview(removed.Content), nil
+		},
+	})
+	l.cmds.Register(Command{
+		Name:        "retry",
+		Usage:       "[--model <name>] [hint]",
+		Description: "Regenerate the last reply, optionally with another model or a hint",
+		Handler: func(ctx context.Context, req CommandRequest) (string, error) {
+			var model string
+			args := req.Args
+			if len(args) >= 2 && (args[0] == "--model" || args[0] == "-m") {
+				model, args = args[1], args[2:]
+			}
+			hint := strings.Join(args, " ")
+
+			// The last turn is only replaced once the new answer succeeds.
+			last, ok := l.sessions.Get(req.SessionKey).RemoveLastTurn()
+			if !ok {
+				return "Nothing to retry.", nil
+			}
+			return l.runTurn(ctx, req.SessionKey, req.ChatID, last.Content,
+				turnOptions{model: model, replaceLast: true, hint: hint})
+		},
+	})
+	l.registerSessionCommands()
+	l.registerMemoryCommand()
+	l.cmds.Register(Command{
+		Name:        "help",
+		Description: "Show available commands",
+		Handler: func(context.Context, CommandRequest) (string, error) {
+			return l.cmds.Help(), nil
+		},
+	})
+}
@@ -2,53 +2,131 @@
 package agent
 
 import (
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
+	"github.com/yosebyte/miniclaw/internal/tools"
 )
 
-// BuildSystemPrompt constructs the system prompt, reading workspace persona files
-// (SOUL.md, AGENTS.md, USER.md) and memory files (MEMORY.md, HISTORY.md).
-func BuildSystemPrompt(workspace, memory, history string) string {
-	var parts []string
+// PromptData is the data available to the system prompt template.
+type PromptData struct {
+	Workspace string
+	Persona   string    // persona file in the workspace, normally SOUL.md
+	Time      time.Time // current time in the user's timezone
+	Source    string    // "telegram", "cli", "cron" or "heartbeat"
+	ChatID    string
+	ChatTitle string
+	UserName  string
+	Tools     []string // names of the tools offered this turn
+	Skills    []Skill
+	Memory    string // MEMORY.md
+	History   string // yesterday's and today's journals
+	Recall    string // older journal entries most relevant to the current message
+}
+
+// defaultSystemTemplate reproduces the built-in layout. A workspace SYSTEM.md
+// replaces it and may use the same variables and functions.
+const defaultSystemTemplate = `{{include .Persona}}
+
+{{include "AGENTS.md"}}
+
+Current time: {{.Time.Format "2006-01-02 15:04 MST"}}
+Workspace: {{.Workspace}}
+
+{{with include "USER.md"}}## About the User
+{{.}}{{end}}
+
+{{with .Skills}}## Skills
+Call load_skill with a skill's name to read its full instructions before using it.
+{{range .}}- {{.Name}}: {{.Description}}
+{{end}}{{end}}
 
-	// Persona and behavioural files
-	if soul := readWorkspaceFile(workspace, "SOUL.md"); soul != "" {
-		parts = append(parts, soul)
+{{with .Memory}}## Long-term Memory
+{{.}}{{end}}
+
+{{with .History}}## Recent Journal
+{{.}}{{end}}
+
+{{with .Recall}}## Relevant History
+Earlier conversations related to this message. Use memory_search or journal_read to look further.
+{{.}}{{end}}
+`
+
+var reBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// BuildSystemPrompt renders the system prompt from workspace/SYSTEM.md if
+// present. Otherwise it uses the default layout: the persona files (SOUL.md or
+// the tenant's persona, AGENTS.md and USER.md), the runtime context, the skills
+// index, MEMORY.md, the recent journals and the older journal entries relevant
+// to the current message.
+//
+// Templates use text/template with these extra functions:
+//
+//	include "FILE"    contents of a file inside the workspace, trimmed ("" if missing)
+//	tail N TEXT       last N bytes of TEXT, cut on a character boundary
+//	hasTool "NAME"    whether the tool is available this turn
+func BuildSystemPrompt(data PromptData) string {
+	if data.Time.IsZero() {
+		data.Time = time.Now()
 	}
-	if agents := readWorkspaceFile(workspace, "AGENTS.md"); agents != "" {
-		parts = append(parts, agents)
+	if data.Persona == "" {
+		data.Persona = "SOUL.md"
+	}
+	funcs := template.FuncMap{
+		"include": func(name string) string { return readWorkspaceFile(data.Workspace, name) },
+		"tail":    tailText,
+		"hasTool": func(name string) bool { return slices.Contains(data.Tools, name) },
 	}
 
-	// Runtime context
-	ctx := fmt.Sprintf("Current time: %s\nWorkspace: %s",
-		time.Now().Format("2006-01-02 15:04 MST"), workspace)
-	parts = append(parts, ctx)
-
-	// User profile
-	if user := readWorkspaceFile(workspace, "USER.md"); user != "" {
-		parts = append(parts, "## About the User\n"+user)
+	src := defaultSystemTemplate
+	custom, err := os.ReadFile(filepath.Join(data.Workspace, "SYSTEM.md"))
+	if err == nil {
+		src = string(custom)
 	}
 
-	// Long-term memory
-	if memory != "" {
-		parts = append(parts, "## Long-term Memory\n"+memory)
+	out, err := renderPrompt(src, funcs, data)
+	if err != nil && src != defaultSystemTemplate {
+		slog.Warn("SYSTEM.md template failed, using default layout", "err", err)
+		out, err = renderPrompt(defaultSystemTemplate, funcs, data)
 	}
+	if err != nil {
+		slog.Error("system prompt template failed", "err", err)
+	}
+	return out
+}
 
-	// Conversation history archive
-	if history != "" {
-		h := history
-		if len(h) > 2000 {
-			h = "...(truncated)\n" + h[len(h)-2000:]
-		}
-		parts = append(parts, "## Conversation History\n"+h)
+func renderPrompt(src string, funcs template.FuncMap, data PromptData) (string, error) {
+	tmpl, err := template.New("SYSTEM.md").Funcs(funcs).Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
 	}
+	out := reBlankLines.ReplaceAllString(sb.String(), "\n\n")
+	return strings.TrimSpace(out), nil
+}
 
-	return strings.Join(parts, "\n\n")
+// tailText returns roughly the last n bytes of s without splitting a UTF-8
+// sequence, marking the cut when one was made.
+func tailText(n int, s string) string {
+	if len(s) <= n {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return "...(truncated)\n" + s[start:]
 }
 
 // BuildMessages creates the full messages list for a chat request.
@@ -63,7 +141,12 @@ func BuildMessages(history []provider.Message, currentContent string) []provider
 }
 
 func readWorkspaceFile(workspace, name string) string {
-	data, err := os.ReadFile(filepath.Join(workspace, name))
+	path, err := tools.ResolveWithin(workspace, name)
+	if err != nil {
+		slog.Warn("include outside the workspace ignored", "file", name, "err", err)
+		return ""
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return ""
 	}
@@ -0,0 +1,162 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// EventKind identifies what happened in the agent loop.
+type EventKind string
+
+const (
+	EventIterationStarted EventKind = "iteration_started"
+	EventToolStarted      EventKind = "tool_started"
+	EventToolFinished     EventKind = "tool_finished"
+	EventTextDelta        EventKind = "text_delta"
+	EventTurnFinished     EventKind = "turn_finished"
+)
+
+// Event is emitted by the loop while a turn runs.
+type Event struct {
+	Kind       EventKind
+	Time       time.Time
+	SessionKey string
+	ChatID     string
+	Iteration  int           // 1-based model call within the turn
+	Tool       string        // tool events
+	Input      string        // short summary of the tool input
+	Duration   time.Duration // tool_finished and turn_finished
+	Err        error         // tool_finished and turn_finished
+	Text       string        // text_delta, and the final reply on turn_finished
+}
+
+// Observer receives loop events. OnEvent is called synchronously from the
+// loop, so implementations must return quickly.
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc adapts a function to an Observer.
+type ObserverFunc func(Event)
+
+// OnEvent calls f(e).
+func (f ObserverFunc) OnEvent(e Event) { f(e) }
+
+// Subscribe registers an observer for all future events.
+func (l *Loop) Subscribe(o Observer) {
+	l.obsMu.Lock()
+	defer l.obsMu.Unlock()
+	l.observers = append(l.observers, o)
+}
+
+func (l *Loop) emit(ctx context.Context, e Event) {
+	c := tools.CallerFrom(ctx)
+	e.Time = time.Now()
+	e.SessionKey = c.SessionKey
+	e.ChatID = c.ChatID
+	l.obsMu.Lock()
+	obs := l.observers
+	l.obsMu.Unlock()
+	for _, o := range obs {
+		o.OnEvent(e)
+	}
+}
+
+// AuditLog is an Observer that appends tool and turn events to a JSONL file.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditLog creates an AuditLog writing to path.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// OnEvent records tool calls and finished turns; other events are skipped.
+func (a *AuditLog) OnEvent(e Event) {
+	if e.Kind != EventToolStarted && e.Kind != EventToolFinished && e.Kind != EventTurnFinished {
+		return
+	}
+	rec := struct {
+		Time       time.Time `json:"time"`
+		Kind       EventKind `json:"kind"`
+		SessionKey string    `json:"session"`
+		ChatID     string    `json:"chatId,omitempty"`
+		Tool       string    `json:"tool,omitempty"`
+		Input      string    `json:"input,omitempty"`
+		DurationMs int64     `json:"durationMs,omitempty"`
+		Error      string    `json:"error,omitempty"`
+	}{
+		Time: e.Time.UTC(), Kind: e.Kind, SessionKey: e.SessionKey, ChatID: e.ChatID,
+		Tool: e.Tool, Input: e.Input, DurationMs: e.Duration.Milliseconds(),
+	}
+	if e.Err != nil {
+		rec.Error = e.Err.Error()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_ = os.MkdirAll(filepath.Dir(a.path), 0700)
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = fmt.Fprintf(f, "%s\n", line)
+}
+
+// summarizeInput picks the most telling argument of a tool call for status
+// lines, e.g. the command of exec or the path of read_file.
+func summarizeInput(input json.RawMessage) string {
+	var args map[string]any
+	if err := json.Unmarshal(input, &args); err != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "path", "url", "name", "query", "id"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			return truncateRunes(v, 80)
+		}
+	}
+	for _, v := range args {
+		if s, ok := v.(string); ok && s != "" {
+			return truncateRunes(s, 80)
+		}
+	}
+	return ""
+}
+
+// FormatStatus renders a compact status line for a tool event,
+// e.g. "🔧 exec: go test ./... (12s)".
+func FormatStatus(e Event) string {
+	icon := "🔧"
+	if e.Err != nil {
+		icon = "⚠️"
+	}
+	line := icon + " " + e.Tool
+	if e.Input != "" {
+		line += ": " + e.Input
+	}
+	if e.Kind == EventToolFinished {
+		d := e.Duration.Round(100 * time.Millisecond)
+		if d >= 10*time.Second {
+			d = d.Round(time.Second)
+		}
+		line += fmt.Sprintf(" (%s)", d)
+	} else {
+		line += " …"
+	}
+	return line
+}
@@ -0,0 +1,88 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportFormats lists the formats accepted by ExportSession.
+var ExportFormats = []string{"text", "markdown", "html", "jsonl"}
+
+// ExportSession writes a session transcript to w. Times are shown in loc.
+func ExportSession(w io.Writer, s *Session, format string, loc *time.Location) error {
+	switch format {
+	case "text", "":
+		for _, m := range s.Messages {
+			fmt.Fprintf(w, "[%s] %s%s: %s\n\n", m.Timestamp.In(loc).Format("2006-01-02 15:04"),
+				strings.ToUpper(m.Role), toolsSuffix(m), m.Content)
+		}
+		return nil
+	case "markdown", "md":
+		fmt.Fprintf(w, "# Session %s\n", s.Key)
+		for _, m := range s.Messages {
+			fmt.Fprintf(w, "\n## %s — %s%s\n\n%s\n", roleTitle(m.Role),
+				m.Timestamp.In(loc).Format("2006-01-02 15:04"), toolsSuffix(m), m.Content)
+		}
+		return nil
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, m := range s.Messages {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "html":
+		type row struct {
+			Role, Title, Time, Tools, Content string
+		}
+		rows := make([]row, 0, len(s.Messages))
+		for _, m := range s.Messages {
+			rows = append(rows, row{
+				Role:    m.Role,
+				Title:   roleTitle(m.Role),
+				Time:    m.Timestamp.In(loc).Format("2006-01-02 15:04"),
+				Tools:   strings.Join(m.ToolsUsed, ", "),
+				Content: m.Content,
+			})
+		}
+		return exportHTML.Execute(w, struct {
+			Key  string
+			Rows []row
+		}{s.Key, rows})
+	default:
+		return fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+func roleTitle(role string) string {
+	if role == "" {
+		return role
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+func toolsSuffix(m SessionMessage) string {
+	if len(m.ToolsUsed) == 0 {
+		return ""
+	}
+	return " [tools: " + strings.Join(m.ToolsUsed, ", ") + "]"
+}
+
+var exportHTML = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Session {{.Key}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;padding:0 1rem;color:#222}
+.msg{border-radius:8px;padding:.75rem 1rem;margin:1rem 0;white-space:pre-wrap}
+.user{background:#e8f0fe}.assistant{background:#f3f3f3}
+.meta{font-size:.8rem;color:#666;margin-bottom:.4rem}
+</style></head><body>
+<h1>Session {{.Key}}</h1>
+{{range .Rows}}<div class="msg {{.Role}}"><div class="meta">{{.Title}} · {{.Time}}{{with .Tools}} · tools: {{.}}{{end}}</div>{{.Content}}</div>
+{{end}}</body></html>
+`))
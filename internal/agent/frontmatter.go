@@ -0,0 +1,87 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"strings"
+)
+
+// frontmatter holds the "key: value" header of a workspace markdown file.
+type frontmatter map[string]string
+
+// parseFrontmatter splits a markdown file into its --- delimited header and body.
+// Only flat "key: value" pairs and "- item" lists are understood; that is all
+// the workspace files need and avoids a YAML dependency.
+func parseFrontmatter(content string) (frontmatter, string) {
+	fm := frontmatter{}
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return fm, content
+	}
+	_, rest, _ := strings.Cut(content, "\n")
+	var lastKey string
+	for {
+		line, tail, found := strings.Cut(rest, "\n")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			return fm, strings.TrimLeft(tail, "\r\n")
+		}
+		if !found {
+			// Unterminated header: treat the whole file as body.
+			return frontmatter{}, content
+		}
+		rest = tail
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && lastKey != "" {
+			if fm[lastKey] != "" {
+				fm[lastKey] += ", "
+			}
+			fm[lastKey] += unquote(item)
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.ToLower(strings.TrimSpace(key))
+		fm[lastKey] = unquote(strings.TrimSpace(value))
+	}
+}
+
+// get returns the first non-empty value among keys.
+func (fm frontmatter) get(keys ...string) string {
+	for _, k := range keys {
+		if v := fm[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// list returns the value of the first matching key as a list, accepting
+// "a, b", "[a, b]" and "a b" forms.
+func (fm frontmatter) list(keys ...string) []string {
+	v := strings.Trim(fm.get(keys...), "[]")
+	if v == "" {
+		return nil
+	}
+	sep := ","
+	if !strings.Contains(v, ",") {
+		sep = " "
+	}
+	var items []string
+	for _, item := range strings.Split(v, sep) {
+		if item = unquote(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// idleNote is shown with the first reply after an idle session was reset.
+const idleNote = "🕰️ It's been a while, so I started a new session. The earlier conversation was saved to memory."
+
+// idleNoteTTL is how long the sweeper keeps a note for a chat that has not
+// written since its session was reset.
+const idleNoteTTL = 7 * 24 * time.Hour
+
+// resetSession clears a session, stops its shell and consolidates the cleared
+// messages into memory in the background. An explicit reset (/new) also stops
+// its background processes and returns how many; an idle reset leaves them
+// running, since a quiet chat may just be waiting for one. The caller holds
+// the session lock.
+func (l *Loop) resetSession(ctx context.Context, sessionKey, chatID string, explicit bool) (stopped int) {
+	var old *Session
+	l.sessions.Update(sessionKey, func(s *Session) {
+		old = s.Clone()
+		s.Clear()
+	})
+	if len(l.todoList(sessionKey)) > 0 {
+		l.setTodos(sessionKey, chatID, nil)
+	}
+	if l.shells != nil {
+		l.shells.Reset(sessionKey)
+	}
+	if explicit {
+		stopped = l.procs.KillSession(sessionKey)
+	}
+	if len(old.Messages) <= old.LastConsolidated {
+		return stopped
+	}
+	memory := l.tenantFor(ctx, chatID).memory
+	go func() {
+		// Archive everything: the messages are gone from the session now.
+		if _, err := memory.Consolidate(context.Background(), l.claude, old, 0); err != nil {
+			slog.Error("memory consolidation failed", "session", old.Key, "err", err)
+		}
+	}()
+	return stopped
+}
+
+// isIdle reports whether the session's last message, or switching to it
+// with /switch or /fork, is older than the chat's idle timeout.
+func (l *Loop) isIdle(s *Session, chatID, userID string, now time.Time) bool {
+	timeout := l.cfg.IdleTimeout(chatID, userID)
+	if timeout <= 0 || len(s.Messages) == 0 {
+		return false
+	}
+	last := s.Messages[len(s.Messages)-1].Timestamp
+	base, _, _ := strings.Cut(s.Key, "#")
+	if idx := l.sessions.Index(base); idx.Sessions[idx.Active] == s.Key && idx.Switched.After(last) {
+		last = idx.Switched
+	}
+	return now.Sub(last) > timeout
+}
+
+// resetIfIdle starts a new session when the chat has been quiet longer than
+// its idle timeout, and returns the note to show the user, if any. A note left
+// by the sweeper for this session is returned as well. The caller holds the
+// session lock.
+func (l *Loop) resetIfIdle(ctx context.Context, sessionKey, chatID string) string {
+	l.idleMu.Lock()
+	_, swept := l.idleReset[sessionKey]
+	delete(l.idleReset, sessionKey)
+	l.idleMu.Unlock()
+
+	s := l.sessions.Get(sessionKey)
+	if l.isIdle(s, chatID, OriginFrom(ctx).UserID, time.Now()) {
+		slog.Info("idle session reset", "session", sessionKey, "last", s.Messages[len(s.Messages)-1].Timestamp)
+		l.resetSession(ctx, sessionKey, chatID, false)
+		return idleNote
+	}
+	if swept {
+		return idleNote
+	}
+	return ""
+}
+
+// RunIdleSweeper resets idle sessions every interval until ctx is cancelled,
+// so they are consolidated even if the chat never speaks again. The user sees
+// the note with their next reply. It also reaps idle persistent shells and
+// drops sessions nobody used for a while from the session cache.
+func (l *Loop) RunIdleSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.sweepIdle(ctx)
+			if n := l.sessions.Evict(sessionCacheIdle); n > 0 {
+				slog.Debug("evicted idle sessions from the cache", "count", n)
+			}
+			if l.shells != nil {
+				if n := l.shells.Reap(l.cfg.ShellIdleTimeout()); n > 0 {
+					slog.Info("reaped idle shells", "count", n)
+				}
+			}
+		}
+	}
+}
+
+// sweepIdle resets the idle sessions that are active in their chat. Named
+// sessions the user switched away from are parked on purpose and kept.
+func (l *Loop) sweepIdle(ctx context.Context) {
+	infos, err := l.sessions.List()
+	if err != nil {
+		slog.Error("idle sweep: listing sessions", "err", err)
+		return
+	}
+	now := time.Now()
+	l.idleMu.Lock()
+	for key, at := range l.idleReset {
+		if now.Sub(at) > idleNoteTTL {
+			delete(l.idleReset, key)
+		}
+	}
+	l.idleMu.Unlock()
+	for _, info := range infos {
+		base, _, _ := strings.Cut(info.Key, "#")
+		if l.sessions.Resolve(base) != info.Key {
+			continue
+		}
+		s := l.sessions.Peek(info.Key)
+		if !l.isIdle(s, s.ChatID, s.UserID, now) {
+			continue
+		}
+		unlock := l.sessions.Lock(info.Key)
+		// Re-check under the lock: a turn may have just finished.
+		s = l.sessions.Get(info.Key)
+		if l.isIdle(s, s.ChatID, s.UserID, now) {
+			slog.Info("idle session reset by sweeper", "session", info.Key)
+			octx := WithOrigin(ctx, Origin{Source: "sweeper", UserID: s.UserID})
+			l.resetSession(octx, info.Key, s.ChatID, false)
+			l.idleMu.Lock()
+			l.idleReset[info.Key] = now
+			l.idleMu.Unlock()
+		}
+		unlock()
+	}
+}
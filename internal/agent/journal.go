@@ -0,0 +1,330 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// Journal kinds: daily entries written by consolidation and the rollups
+// summarising them.
+const (
+	JournalDaily   = "daily"
+	JournalWeekly  = "weekly"
+	JournalMonthly = "monthly"
+)
+
+// maxJournalRead caps the text journal_read returns and a rollup reads.
+const maxJournalRead = 64 * 1024
+
+// journalFile is one journal on disk.
+type journalFile struct {
+	kind  string
+	label string    // 2026-10-18, 2026-W42 or 2026-10
+	start time.Time // first day covered
+	end   time.Time // day after the last day covered
+	path  string
+}
+
+var reDailyJournal = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.md$`)
+
+// SetLocation sets the timezone that decides which day's journal an entry
+// belongs to. The default is the local timezone.
+func (m *MemoryStore) SetLocation(loc *time.Location) {
+	m.loc = loc
+}
+
+func (m *MemoryStore) location() *time.Location {
+	if m.loc == nil {
+		return time.Local
+	}
+	return m.loc
+}
+
+func (m *MemoryStore) journalDir() string {
+	return filepath.Join(m.workspace, "memory")
+}
+
+func (m *MemoryStore) dailyPath(day time.Time) string {
+	return filepath.Join(m.journalDir(), day.In(m.location()).Format("2006-01-02")+".md")
+}
+
+func weekLabel(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", y, w)
+}
+
+// weekStart returns the Monday starting t's ISO week.
+func weekStart(t time.Time) time.Time {
+	d := startOfDay(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+// AppendJournal appends an entry to the journal of the day t falls on,
+// followed by a blank line so entries can be told apart when searching.
+func (m *MemoryStore) AppendJournal(t time.Time, entry string) error {
+	m.migrateHistory()
+	if err := os.MkdirAll(m.journalDir(), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.dailyPath(t), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\n\n", strings.TrimSpace(entry))
+	return err
+}
+
+// ReadJournal returns the daily journal of the day t falls on.
+func (m *MemoryStore) ReadJournal(t time.Time) string {
+	m.migrateHistory()
+	data, _ := os.ReadFile(m.dailyPath(t))
+	return strings.TrimSpace(string(data))
+}
+
+// RecentJournal returns yesterday's and today's journals for the system prompt.
+func (m *MemoryStore) RecentJournal(now time.Time) string {
+	now = now.In(m.location())
+	var parts []string
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if text := m.ReadJournal(day); text != "" {
+			parts = append(parts, fmt.Sprintf("### %s\n%s", day.Format("2006-01-02 (Monday)"), text))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// journals lists the journal files of the given kind ("" for all), oldest first.
+func (m *MemoryStore) journals(kind string) []journalFile {
+	m.migrateHistory()
+	loc := m.location()
+	var files []journalFile
+	if kind == "" || kind == JournalDaily {
+		entries, _ := os.ReadDir(m.journalDir())
+		for _, e := range entries {
+			if e.IsDir() || !reDailyJournal.MatchString(e.Name()) {
+				continue
+			}
+			label := strings.TrimSuffix(e.Name(), ".md")
+			day, err := time.ParseInLocation("2006-01-02", label, loc)
+			if err != nil {
+				continue
+			}
+			files = append(files, journalFile{JournalDaily, label, day, day.AddDate(0, 0, 1), filepath.Join(m.journalDir(), e.Name())})
+		}
+	}
+	if kind == "" || kind == JournalWeekly {
+		paths, _ := filepath.Glob(filepath.Join(m.journalDir(), JournalWeekly, "*.md"))
+		for _, path := range paths {
+			label := strings.TrimSuffix(filepath.Base(path), ".md")
+			var y, w int
+			if _, err := fmt.Sscanf(label, "%d-W%d", &y, &w); err != nil {
+				continue
+			}
+			// Jan 4th is always in ISO week 1.
+			start := weekStart(time.Date(y, 1, 4, 0, 0, 0, 0, loc)).AddDate(0, 0, 7*(w-1))
+			files = append(files, journalFile{JournalWeekly, label, start, start.AddDate(0, 0, 7), path})
+		}
+	}
+	if kind == "" || kind == JournalMonthly {
+		paths, _ := filepath.Glob(filepath.Join(m.journalDir(), JournalMonthly, "*.md"))
+		for _, path := range paths {
+			label := strings.TrimSuffix(filepath.Base(path), ".md")
+			start, err := time.ParseInLocation("2006-01", label, loc)
+			if err != nil {
+				continue
+			}
+			files = append(files, journalFile{JournalMonthly, label, start, start.AddDate(0, 1, 0), path})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].start.Before(files[j].start) })
+	return files
+}
+
+// ReadJournals returns the journals of one kind overlapping [from, to], each
+// under a heading with its date or period.
+func (m *MemoryStore) ReadJournals(kind string, from, to time.Time) string {
+	to = startOfDay(to.In(m.location())).AddDate(0, 0, 1)
+	from = startOfDay(from.In(m.location()))
+	var sb strings.Builder
+	for _, f := range m.journals(kind) {
+		if !f.start.Before(to) || !f.end.After(from) {
+			continue
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n%s", f.label, strings.TrimSpace(string(data)))
+		if sb.Len() > maxJournalRead {
+			return strings.ToValidUTF8(sb.String()[:maxJournalRead], "") + "\n\n… (truncated; request a shorter range)"
+		}
+	}
+	return sb.String()
+}
+
+// migrateHistory moves the entries of a pre-journal HISTORY.md into daily
+// journals, dating each by its leading "[YYYY-MM-DD" timestamp, and renames the
+// file to HISTORY.md.migrated. It runs once per store.
+func (m *MemoryStore) migrateHistory() {
+	m.migrateOnce.Do(func() {
+		path := filepath.Join(m.workspace, "HISTORY.md")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		fi, _ := os.Stat(path)
+		day := fi.ModTime()
+		byDay := make(map[string][]string)
+		var order []string
+		for _, entry := range splitHistory(string(data)) {
+			if len(entry) >= 11 && reHistoryStart.MatchString(entry) {
+				if t, err := time.ParseInLocation("2006-01-02", entry[1:11], m.location()); err == nil {
+					day = t
+				}
+			}
+			key := m.dailyPath(day)
+			if _, ok := byDay[key]; !ok {
+				order = append(order, key)
+			}
+			byDay[key] = append(byDay[key], entry)
+		}
+		if err := os.MkdirAll(m.journalDir(), 0755); err != nil {
+			slog.Error("migrating HISTORY.md", "err", err)
+			return
+		}
+		for _, key := range order {
+			f, err := os.OpenFile(key, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				slog.Error("migrating HISTORY.md", "err", err)
+				return
+			}
+			_, err = f.WriteString(strings.Join(byDay[key], "\n\n") + "\n\n")
+			f.Close()
+			if err != nil {
+				slog.Error("migrating HISTORY.md", "err", err)
+				return
+			}
+		}
+		if err := os.Rename(path, path+".migrated"); err != nil {
+			slog.Error("migrating HISTORY.md", "err", err)
+			return
+		}
+		slog.Info("migrated HISTORY.md to daily journals", "workspace", m.workspace, "days", len(order))
+	})
+}
+
+// Rollups writes the missing weekly and monthly summaries for periods that
+// ended before now and have daily journals, returning how many it wrote.
+func (m *MemoryStore) Rollups(ctx context.Context, claude ChatClient, now time.Time) (int, error) {
+	m.consolidating.Lock()
+	defer m.consolidating.Unlock()
+
+	today := startOfDay(now.In(m.location()))
+	daily := m.journals(JournalDaily)
+	type period struct {
+		kind, label string
+		start, end  time.Time
+	}
+	var due []period
+	seen := make(map[string]bool)
+	for _, d := range daily {
+		ws := weekStart(d.start)
+		ms := time.Date(d.start.Year(), d.start.Month(), 1, 0, 0, 0, 0, d.start.Location())
+		for _, p := range []period{
+			{JournalWeekly, weekLabel(d.start), ws, ws.AddDate(0, 0, 7)},
+			{JournalMonthly, d.start.Format("2006-01"), ms, ms.AddDate(0, 1, 0)},
+		} {
+			key := p.kind + "/" + p.label
+			if seen[key] || p.end.After(today) {
+				continue
+			}
+			seen[key] = true
+			if _, err := os.Stat(filepath.Join(m.journalDir(), p.kind, p.label+".md")); err == nil {
+				continue
+			}
+			due = append(due, p)
+		}
+	}
+
+	n := 0
+	for _, p := range due {
+		text := m.ReadJournals(JournalDaily, p.start, p.end.AddDate(0, 0, -1))
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		summary, err := summarizeJournal(ctx, claude, p.kind, p.label, text)
+		if err != nil {
+			return n, fmt.Errorf("%s rollup %s: %w", p.kind, p.label, err)
+		}
+		dir := filepath.Join(m.journalDir(), p.kind)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return n, err
+		}
+		if err := writeFileAtomic(filepath.Join(dir, p.label+".md"), []byte(summary+"\n"), 0644); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func summarizeJournal(ctx context.Context, claude ChatClient, kind, label, text string) (string, error) {
+	prompt := fmt.Sprintf(`Below are the daily journal entries for %s. Write a %s summary in Markdown:
+the main topics, decisions, outcomes and open threads, grouped by theme, at most 300 words.
+Reply with the summary only.
+
+%s`, label, kind, text)
+	resp, err := claude.ChatWith(ctx, provider.ChatOptions{}, "You summarise personal assistant journals.", []provider.Message{
+		{Role: "user", Content: prompt},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, block := range resp.Content {
+		if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
+			return strings.TrimSpace(block.Text), nil
+		}
+	}
+	return "", fmt.Errorf("empty response")
+}
+
+// RunJournalRollups writes due rollups for every workspace at startup and then
+// every interval, until ctx is cancelled.
+func (l *Loop) RunJournalRollups(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, ws := range l.cfg.Workspaces() {
+			n, err := l.memoryFor(ws).Rollups(ctx, l.claude, time.Now())
+			if err != nil {
+				slog.Error("journal rollup failed", "workspace", ws, "err", err)
+			} else if n > 0 {
+				slog.Info("journal rollups written", "workspace", ws, "count", n)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
-	"strings"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/yosebyte/miniclaw/internal/config"
 	"github.com/yosebyte/miniclaw/internal/provider"
@@ -16,6 +18,28 @@ import (
 // SendFunc sends a message to a chat (Telegram).
 type SendFunc func(chatID, text string) error
 
+// Origin describes where an inbound message came from. Channels attach it to
+// the context passed to ProcessMessage.
+type Origin struct {
+	Source    string // "telegram", "cli", "cron" or "heartbeat"
+	UserID    string
+	UserName  string
+	ChatTitle string
+}
+
+type originKey struct{}
+
+// WithOrigin returns a context carrying o.
+func WithOrigin(ctx context.Context, o Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, o)
+}
+
+// OriginFrom returns the Origin stored in ctx, if any.
+func OriginFrom(ctx context.Context) Origin {
+	o, _ := ctx.Value(originKey{}).(Origin)
+	return o
+}
+
 // CronService is the interface the loop needs from the cron service.
 type CronService interface {
 	AddJob(name, schedule, message, chatID string) error
@@ -28,8 +52,26 @@ type Loop struct {
 	cfg      *config.Config
 	claude   *provider.Claude
 	sessions *SessionManager
-	memory   *MemoryStore
 	reg      *tools.Registry
+	cmds     *CommandRegistry
+	prompts  *PromptCommands
+	search   *SessionSearch
+
+	idleMu    sync.Mutex
+	idleReset map[string]time.Time // sessions reset by the sweeper, awaiting the user's note
+
+	memMu    sync.Mutex
+	memories map[string]*MemoryStore // workspace -> store
+
+	obsMu     sync.Mutex
+	observers []Observer
+
+	todoMu sync.Mutex
+	todos  map[string][]tools.TodoItem // session key -> task list
+	todoFn TodoFunc
+
+	shells *tools.ShellPool // persistent exec shells; nil unless enabled
+	procs  *tools.ProcessManager
 
 	// mutable context updated per-message so tools can route replies
 	currentChatID string
@@ -40,19 +82,48 @@ type Loop struct {
 // NewLoop creates a Loop. Call SetSendFunc and SetCronService before starting.
 func NewLoop(cfg *config.Config, claude *provider.Claude) *Loop {
 	workspace := cfg.WorkspacePath()
-	sessDir := filepath.Join(filepath.Dir(workspace), "sessions")
+	sessDir := cfg.SessionsPath()
 
 	l := &Loop{
-		cfg:      cfg,
-		claude:   claude,
-		sessions: NewSessionManager(sessDir),
-		memory:   NewMemoryStore(workspace),
-		reg:      tools.NewRegistry(),
+		cfg:       cfg,
+		claude:    claude,
+		sessions:  NewSessionManager(sessDir),
+		memories:  make(map[string]*MemoryStore),
+		idleReset: make(map[string]time.Time),
+		todos:     make(map[string][]tools.TodoItem),
+		reg:       tools.NewRegistry(),
+		cmds:      NewCommandRegistry(),
 	}
 	l.registerBaseTools()
+	l.registerTodoTools()
+	l.registerMemoryTools()
+	l.registerBuiltinCommands()
+	l.registerSearch()
+	l.prompts = NewPromptCommands(filepath.Join(workspace, "commands"), l.cmds, l.runPromptCommand)
+	l.prompts.Reload()
 	return l
 }
 
+// Commands returns the slash-command registry shared by all channels.
+func (l *Loop) Commands() *CommandRegistry {
+	return l.cmds
+}
+
+// Close flushes pending session writes and stops persistent shells and
+// background processes. Call it before exiting.
+func (l *Loop) Close() error {
+	if l.shells != nil {
+		l.shells.Close()
+	}
+	l.procs.Close()
+	return l.sessions.Flush()
+}
+
+// PromptCommands returns the loader for workspace/commands/*.md.
+func (l *Loop) PromptCommands() *PromptCommands {
+	return l.prompts
+}
+
 // SetSendFunc sets the send callback and registers the send_message tool.
 func (l *Loop) SetSendFunc(sendFn SendFunc) {
 	l.sendFn = sendFn
@@ -75,55 +146,170 @@ func (l *Loop) SetCronService(cronSvc CronService) {
 }
 
 func (l *Loop) registerBaseTools() {
-	l.reg.Register(tools.ReadFileTool{})
-	l.reg.Register(tools.WriteFileTool{})
-	l.reg.Register(tools.EditFileTool{})
-	l.reg.Register(tools.ListDirTool{})
-	l.reg.Register(tools.ExecTool{})
+	fsCfg := l.cfg.Tools.FS
+	policy := tools.NewPathPolicy(fsCfg.AllowedRoots, fsCfg.ReadOnlyRoots, fsCfg.DeniedGlobs)
+	l.reg.Register(tools.ReadFileTool{Policy: policy})
+	l.reg.Register(tools.WriteFileTool{Policy: policy})
+	l.reg.Register(tools.EditFileTool{Policy: policy})
+	l.reg.Register(tools.ListDirTool{Policy: policy})
+	var sandbox *tools.Sandbox
+	if sb := l.cfg.Tools.Exec.Sandbox; sb.Enabled {
+		sandbox = tools.NewSandbox(sb.Network, sb.CPUSeconds, sb.MemoryMB, sb.MaxProcs)
+	}
+	var rules []tools.CommandRuleSet
+	for _, r := range l.cfg.Tools.Exec.Rules {
+		rules = append(rules, tools.CommandRuleSet{ChatIDs: r.ChatIDs, UserIDs: r.UserIDs, Allow: r.Allow, Deny: r.Deny})
+	}
+	cmdPolicy, err := tools.NewCommandPolicy(rules)
+	if err != nil {
+		slog.Error("invalid exec rules; denying all commands", "err", err)
+	}
+	if l.cfg.Tools.Exec.PersistentShell {
+		l.shells = tools.NewShellPool(sandbox)
+	}
+	l.reg.Register(tools.ExecTool{Sandbox: sandbox, Shells: l.shells, Policy: cmdPolicy})
+	l.registerProcessTools(sandbox, cmdPolicy)
 	l.reg.Register(tools.NewWebFetchTool())
+	l.reg.Register(tools.NewLoadSkillTool(func(ctx context.Context, name string) (string, error) {
+		return LoadSkill(tools.CallerFrom(ctx).Workspace, name)
+	}))
 }
 
 // ProcessMessage handles one inbound message and returns the assistant reply.
-// chatID is used for tool routing (send_message, cron_add).
-func (l *Loop) ProcessMessage(ctx context.Context, sessionKey, chatID, userMsg string) (string, error) {
+// chatID is used for tool routing (send_message, cron_add). chatKey names
+// the chat; the message goes to whichever of its named sessions is active.
+func (l *Loop) ProcessMessage(ctx context.Context, chatKey, chatID, userMsg string) (string, error) {
 	l.currentChatID = chatID
-	session := l.sessions.Get(sessionKey)
+	sessionKey := l.sessions.Resolve(chatKey)
+	unlock := l.sessions.Lock(sessionKey)
+	defer unlock()
 
-	switch strings.TrimSpace(strings.ToLower(userMsg)) {
-	case "/new":
-		old := *session
-		session.Clear()
-		_ = l.sessions.Save(session)
-		go func() {
-			l.memory.Consolidate(context.Background(), l.claude, &old, l.memWindow())
-		}()
-		return "New session started. Memory consolidation in progress.", nil
-	case "/help":
-		return "🐾 miniclaw commands:\n/new — Start a new conversation\n/help — Show available commands", nil
+	l.prompts.Reload()
+	if reply, handled, err := l.cmds.Dispatch(ctx, chatKey, sessionKey, chatID, userMsg); handled {
+		return reply, err
+	}
+	note := l.resetIfIdle(ctx, sessionKey, chatID)
+	reply, err := l.runTurn(ctx, sessionKey, chatID, userMsg, turnOptions{})
+	if note != "" && err == nil {
+		reply = note + "\n\n" + reply
 	}
+	return reply, err
+}
+
+func (l *Loop) runPromptCommand(ctx context.Context, req CommandRequest, p PromptCommand) (string, error) {
+	return l.runTurn(ctx, req.SessionKey, req.ChatID, p.Expand(req.Args, req.RawArgs), turnOptions{allowedTools: p.AllowedTools})
+}
+
+// tenant is the workspace, persona and memory that serve one request.
+type tenant struct {
+	workspace string
+	persona   string
+	memory    *MemoryStore
+}
+
+// tenantFor resolves the tenant for a request from chatID and the origin's user.
+func (l *Loop) tenantFor(ctx context.Context, chatID string) tenant {
+	workspace, persona := l.cfg.ResolveTenant(chatID, OriginFrom(ctx).UserID)
+	return tenant{workspace: workspace, persona: persona, memory: l.memoryFor(workspace)}
+}
+
+// memoryFor returns the shared MemoryStore of a workspace.
+func (l *Loop) memoryFor(workspace string) *MemoryStore {
+	l.memMu.Lock()
+	defer l.memMu.Unlock()
+	m, ok := l.memories[workspace]
+	if !ok {
+		m = NewMemoryStore(workspace)
+		m.SetLocation(l.cfg.Location())
+		l.memories[workspace] = m
+	}
+	return m
+}
+
+// turnOptions adjusts a single agent turn.
+type turnOptions struct {
+	allowedTools []string // restricts the tools offered to the model; nil means all
+	model        string   // overrides the configured model
+	replaceLast  bool     // the turn redoes the session's last one, which it replaces on success
+	hint         string   // extra guidance sent with the message but not recorded
+}
+
+// runTurn sends userMsg to the model with the session's history and records the exchange.
+func (l *Loop) runTurn(ctx context.Context, sessionKey, chatID, userMsg string, opts turnOptions) (string, error) {
+	session := l.sessions.Get(sessionKey)
+	if opts.replaceLast {
+		session.RemoveLastTurn() // Get returns a copy; the stored turn stays until this one succeeds
+	}
+	l.loadTodos(session)
+	t := l.tenantFor(ctx, chatID)
+	origin := OriginFrom(ctx)
+	ctx = tools.WithCaller(ctx, tools.Caller{
+		SessionKey: sessionKey,
+		ChatID:     chatID,
+		UserID:     origin.UserID,
+		Workspace:  t.workspace,
+	})
 
 	memWindow := l.memWindow()
 	if len(session.Messages) > memWindow {
+		snap := session.Clone()
 		go func() {
-			snap := *session
-			l.memory.Consolidate(context.Background(), l.claude, &snap, memWindow)
-			session.LastConsolidated = snap.LastConsolidated
-			_ = l.sessions.Save(session)
+			if _, err := t.memory.Consolidate(context.Background(), l.claude, snap, memWindow/2); err != nil {
+				slog.Error("memory consolidation failed", "session", sessionKey, "err", err)
+				return
+			}
+			l.sessions.Update(sessionKey, func(s *Session) {
+				// The turn may have appended, undone or cleared messages meanwhile.
+				if snap.LastConsolidated > s.LastConsolidated && snap.LastConsolidated <= len(s.Messages) {
+					s.LastConsolidated = snap.LastConsolidated
+				}
+			})
 		}()
 	}
 
-	systemPrompt := BuildSystemPrompt(l.cfg.WorkspacePath(), l.memory.ReadMemory(), l.memory.ReadHistory())
+	toolNames := l.reg.Names()
+	if opts.allowedTools != nil {
+		toolNames = opts.allowedTools
+	}
+	now := time.Now().In(l.cfg.Location())
+	systemPrompt := BuildSystemPrompt(PromptData{
+		Workspace: t.workspace,
+		Persona:   t.persona,
+		Time:      now,
+		Source:    origin.Source,
+		ChatID:    chatID,
+		ChatTitle: origin.ChatTitle,
+		UserName:  origin.UserName,
+		Tools:     toolNames,
+		Skills:    ListSkills(t.workspace),
+		Memory:    t.memory.ReadMemory(),
+		History:   t.memory.RecentJournal(now),
+		Recall:    t.memory.RelevantHistory(userMsg, now),
+	})
 	history := session.RecentMessages(memWindow)
-	messages := BuildMessages(history, userMsg)
+	prompt := userMsg
+	if opts.hint != "" {
+		prompt += "\n\n(Hint for this answer: " + opts.hint + ")"
+	}
+	messages := BuildMessages(history, prompt)
 
-	finalContent, toolsUsed, err := l.runLoop(ctx, systemPrompt, messages)
+	start := time.Now()
+	finalContent, toolsUsed, err := l.runLoop(ctx, systemPrompt, messages, opts)
+	l.emit(ctx, Event{Kind: EventTurnFinished, Duration: time.Since(start), Err: err, Text: finalContent})
 	if err != nil {
 		return "", err
 	}
 
-	session.Add("user", userMsg)
-	session.Add("assistant", finalContent, toolsUsed...)
-	_ = l.sessions.Save(session)
+	todos := l.todoList(sessionKey)
+	l.sessions.Update(sessionKey, func(s *Session) {
+		s.ChatID, s.UserID = chatID, origin.UserID
+		if opts.replaceLast {
+			s.RemoveLastTurn()
+		}
+		s.Add("user", userMsg)
+		s.Add("assistant", finalContent, toolsUsed...)
+		s.Todos = todos
+	})
 
 	return finalContent, nil
 }
@@ -135,16 +321,23 @@ func (l *Loop) memWindow() int {
 	return 50
 }
 
-func (l *Loop) runLoop(ctx context.Context, system string, messages []provider.Message) (string, []string, error) {
+func (l *Loop) runLoop(ctx context.Context, system string, messages []provider.Message, opts turnOptions) (string, []string, error) {
 	maxIter := l.cfg.Provider.MaxIterations
 	if maxIter == 0 {
 		maxIter = 20
 	}
 	toolDefs := l.reg.Definitions()
+	if opts.allowedTools != nil {
+		toolDefs = l.reg.DefinitionsFor(opts.allowedTools)
+	}
 	var toolsUsed []string
+	sessionKey := tools.CallerFrom(ctx).SessionKey
 
-	for range maxIter {
-		resp, err := l.claude.Chat(ctx, system, messages, toolDefs)
+	for i := range maxIter {
+		l.emit(ctx, Event{Kind: EventIterationStarted, Iteration: i + 1})
+		// The task list can change between iterations, so it is re-rendered each time.
+		resp, err := l.claude.ChatWith(ctx, provider.ChatOptions{Model: opts.model},
+			system+l.todoPrompt(sessionKey), messages, toolDefs)
 		if err != nil {
 			return "", toolsUsed, fmt.Errorf("LLM error: %w", err)
 		}
@@ -161,6 +354,9 @@ func (l *Loop) runLoop(ctx context.Context, system string, messages []provider.M
 			}
 		}
 
+		if textContent != "" {
+			l.emit(ctx, Event{Kind: EventTextDelta, Iteration: i + 1, Text: textContent})
+		}
 		if resp.StopReason == "end_turn" || len(toolCalls) == 0 {
 			return textContent, toolsUsed, nil
 		}
@@ -178,8 +374,19 @@ func (l *Loop) runLoop(ctx context.Context, system string, messages []provider.M
 				input = input[:200] + "..."
 			}
 			slog.Info("tool call", "name", tc.Name, "input", input)
+			summary := summarizeInput(tc.Input)
+			l.emit(ctx, Event{Kind: EventToolStarted, Iteration: i + 1, Tool: tc.Name, Input: summary})
+			toolStart := time.Now()
 
-			result, execErr := l.reg.Execute(ctx, tc.Name, tc.Input)
+			var result string
+			var execErr error
+			if opts.allowedTools != nil && !slices.Contains(opts.allowedTools, tc.Name) {
+				execErr = fmt.Errorf("tool %s is not allowed for this command", tc.Name)
+			} else {
+				result, execErr = l.reg.Execute(ctx, tc.Name, tc.Input)
+			}
+			l.emit(ctx, Event{Kind: EventToolFinished, Iteration: i + 1, Tool: tc.Name, Input: summary,
+				Duration: time.Since(toolStart), Err: execErr})
 			isError := false
 			if execErr != nil {
 				result = "Error: " + execErr.Error()
@@ -9,14 +9,25 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
 )
 
-// MemoryStore manages MEMORY.md and HISTORY.md in the workspace.
+// MemoryStore manages long-term memory in the workspace: MEMORY.md, rendered
+// from structured entries (see MemoryEntry), and the dated journals under
+// memory/ that consolidation writes conversation summaries to.
 type MemoryStore struct {
 	workspace string
+	mu        sync.Mutex // guards the entries file and MEMORY.md
+	recall    memoryIndex
+
+	loc         *time.Location
+	migrateOnce sync.Once
+
+	consolidating sync.Mutex           // serializes Consolidate and Rollups
+	consolidated  map[string]time.Time // session key -> newest message consolidated
 }
 
 // NewMemoryStore creates a MemoryStore for the given workspace directory.
@@ -30,54 +41,134 @@ func (m *MemoryStore) ReadMemory() string {
 	return string(data)
 }
 
-// WriteMemory overwrites MEMORY.md.
-func (m *MemoryStore) WriteMemory(content string) error {
-	return m.writeFile("MEMORY.md", content)
-}
-
-// AppendHistory appends an entry to HISTORY.md.
-func (m *MemoryStore) AppendHistory(entry string) error {
-	path := filepath.Join(m.workspace, "HISTORY.md")
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// WriteMemory replaces the memory with the facts in content, written in the
+// MEMORY.md layout. Facts keeping their "(#id)" tag keep their metadata.
+// It reports whether anything changed.
+func (m *MemoryStore) WriteMemory(content, session string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
 	if err != nil {
-		return err
+		return false, err
+	}
+	before := renderMemory(mf.Entries)
+	mf.sync(parseMemory(content), session)
+	if renderMemory(mf.Entries) == before {
+		return false, nil
 	}
-	defer f.Close()
-	_, err = fmt.Fprintln(f, entry)
-	return err
+	return true, m.saveEntries(mf, Revision{Reason: ReasonManual, Session: session})
 }
 
-// ReadHistory returns the content of HISTORY.md.
-func (m *MemoryStore) ReadHistory() string {
-	data, _ := os.ReadFile(filepath.Join(m.workspace, "HISTORY.md"))
-	return string(data)
+// ChatClient is the part of the provider that consolidation needs.
+type ChatClient interface {
+	ChatWith(ctx context.Context, opts provider.ChatOptions, system string, messages []provider.Message, tools []provider.ToolDefinition) (*provider.ChatResponse, error)
 }
 
-func (m *MemoryStore) writeFile(name, content string) error {
-	if err := os.MkdirAll(m.workspace, 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(filepath.Join(m.workspace, name), []byte(content), 0644)
+// consolidationAttempts and consolidationBackoff control retries of a failed
+// consolidation request before its messages are queued for later.
+var (
+	consolidationAttempts = 3
+	consolidationBackoff  = 2 * time.Second
+)
+
+// saveConsolidationTool is the tool the model is forced to call, so its
+// answer always arrives as JSON matching the schema.
+var saveConsolidationTool = provider.ToolDefinition{
+	Name:        "save_consolidation",
+	Description: "Save the summary of the conversation and the changes to long-term memory.",
+	InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "history_entry": {"type": "string", "description": "2-5 sentences summarizing the key events, decisions and topics, starting with the timestamp in brackets."},
+    "memory_changes": {
+      "type": "object",
+      "properties": {
+        "add": {"type": "array", "items": {"type": "object", "properties": {
+          "category": {"type": "string"},
+          "content":  {"type": "string"}
+        }, "required": ["content"]}},
+        "update": {"type": "array", "items": {"type": "object", "properties": {
+          "id":       {"type": "integer"},
+          "category": {"type": "string"},
+          "content":  {"type": "string"}
+        }, "required": ["id", "content"]}},
+        "remove": {"type": "array", "items": {"type": "integer"}}
+      }
+    }
+  },
+  "required": ["history_entry", "memory_changes"]
+}`),
+}
+
+// ConsolidationResult reports what a consolidation did.
+type ConsolidationResult struct {
+	Start, End   int // message range consolidated; End is the new LastConsolidated
+	HistoryEntry string
+	Changes      MemoryChanges
+	Applied      int  // memory changes that took effect
+	Attempts     int  // model requests made
+	Queued       bool // the request kept failing and the messages were queued for a later run
+	Replayed     int  // queued batches from earlier failures processed by this run
 }
 
-// Consolidate summarises old messages into HISTORY.md and updates MEMORY.md using Claude.
-func (m *MemoryStore) Consolidate(ctx context.Context, claude *provider.Claude, session *Session, memWindow int) {
-	keepCount := memWindow / 2
-	if len(session.Messages) <= keepCount {
-		return
+// consolidationBatch is a run of messages waiting to be consolidated, stored
+// in workspace/memory/pending/ when consolidation fails.
+type consolidationBatch struct {
+	SessionKey string           `json:"sessionKey"`
+	Messages   []SessionMessage `json:"messages"`
+}
+
+// Consolidate summarises the session's unconsolidated messages into the journal
+// and applies the proposed changes to the memory entries, leaving the most
+// recent keep messages alone. Runs are serialized per workspace and never
+// summarise a message twice. If the model keeps failing, the messages are
+// queued and retried by the next run, so the range still counts as done.
+// session.LastConsolidated is advanced to result.End.
+func (m *MemoryStore) Consolidate(ctx context.Context, claude ChatClient, session *Session, keep int) (ConsolidationResult, error) {
+	m.consolidating.Lock()
+	defer m.consolidating.Unlock()
+
+	res := ConsolidationResult{Start: session.LastConsolidated, End: session.LastConsolidated}
+	res.Replayed = m.replayPending(ctx, claude)
+
+	end := len(session.Messages) - keep
+	// Skip messages a concurrent run (e.g. /new racing the window threshold)
+	// already consolidated from another copy of this session.
+	done := m.consolidated[session.Key]
+	for res.Start < end && !session.Messages[res.Start].Timestamp.After(done) {
+		res.Start++
 	}
+	if end <= res.Start {
+		if end > res.End {
+			res.End = end
+			session.LastConsolidated = end
+		}
+		return res, nil
+	}
+	batch := consolidationBatch{SessionKey: session.Key, Messages: session.Messages[res.Start:end]}
 
-	end := len(session.Messages) - keepCount
-	if end <= session.LastConsolidated {
-		return
+	err := m.consolidateBatch(ctx, claude, batch, &res)
+	if err != nil {
+		if qerr := m.queueBatch(batch); qerr != nil {
+			return res, fmt.Errorf("consolidation failed (%v) and could not be queued: %w", err, qerr)
+		}
+		res.Queued = true
+		slog.Warn("memory consolidation failed, queued for retry", "session", session.Key, "attempts", res.Attempts, "err", err)
 	}
-	oldMsgs := session.Messages[session.LastConsolidated:end]
-	if len(oldMsgs) == 0 {
-		return
+	m.markConsolidated(session.Key, batch.Messages[len(batch.Messages)-1].Timestamp)
+	res.End = end
+	session.LastConsolidated = end
+	if err == nil {
+		slog.Info("memory consolidation done", "session", session.Key, "messages", end-res.Start, "changes", res.Applied)
 	}
+	return res, nil
+}
 
+// consolidateBatch asks the model for a summary and memory changes, retrying
+// failures, then records them.
+func (m *MemoryStore) consolidateBatch(ctx context.Context, claude ChatClient, batch consolidationBatch, res *ConsolidationResult) error {
 	var lines []string
-	for _, msg := range oldMsgs {
+	for _, msg := range batch.Messages {
 		tools := ""
 		if len(msg.ToolsUsed) > 0 {
 			tools = " [tools: " + strings.Join(msg.ToolsUsed, ", ") + "]"
@@ -85,80 +176,132 @@ func (m *MemoryStore) Consolidate(ctx context.Context, claude *provider.Claude,
 		ts := msg.Timestamp.Format("2006-01-02 15:04")
 		lines = append(lines, fmt.Sprintf("[%s] %s%s: %s", ts, strings.ToUpper(msg.Role), tools, msg.Content))
 	}
-	conversation := strings.Join(lines, "\n")
-	currentMemory := m.ReadMemory()
-
-	memSnippet := currentMemory
-	if memSnippet == "" {
-		memSnippet = "(empty)"
+	entries, err := m.Entries("")
+	if err != nil {
+		return err
 	}
 
-	prompt := fmt.Sprintf(`You are a memory consolidation agent. Process this conversation and return a JSON object with exactly two keys:
+	prompt := fmt.Sprintf(`Process this conversation and call save_consolidation with:
 
-1. "history_entry": A paragraph (2-5 sentences) summarizing the key events/decisions/topics. Start with a timestamp like [%s].
+1. history_entry: A paragraph (2-5 sentences) summarizing the key events/decisions/topics. Start with a timestamp like [%s].
 
-2. "memory_update": The updated long-term memory content. Add any new facts: user preferences, personal info, project context, technical decisions. If nothing new, return the existing content unchanged.
+2. memory_changes: Changes to the long-term memory entries. Add new lasting facts (user preferences, personal info, project context, technical decisions), update facts that changed (by id), and remove only facts the conversation shows are wrong or obsolete. Use short lowercase categories such as "preferences", "people", "projects". Leave it empty if nothing changes.
 
-## Current Long-term Memory
+## Current Long-term Memory Entries
 %s
 
 ## Conversation to Process
-%s
-
-Respond with ONLY valid JSON, no markdown fences.`,
-		time.Now().Format("2006-01-02 15:04"),
-		memSnippet,
-		conversation,
+%s`,
+		batch.Messages[0].Timestamp.Format("2006-01-02 15:04"),
+		FormatMemoryEntries(entries, time.UTC),
+		strings.Join(lines, "\n"),
 	)
 
-	resp, err := claude.Chat(ctx, "You are a memory consolidation agent. Respond only with valid JSON.", []provider.Message{
-		{Role: "user", Content: prompt},
-	}, nil)
-	if err != nil {
-		slog.Error("memory consolidation failed", "err", err)
-		return
+	var out struct {
+		HistoryEntry  string        `json:"history_entry"`
+		MemoryChanges MemoryChanges `json:"memory_changes"`
 	}
-
-	text := ""
-	for _, block := range resp.Content {
-		if block.Type == "text" {
-			text = block.Text
+	opts := provider.ChatOptions{ToolChoice: &provider.ToolChoice{Type: "tool", Name: saveConsolidationTool.Name}}
+	for attempt := 1; ; attempt++ {
+		res.Attempts++
+		err = func() error {
+			resp, err := claude.ChatWith(ctx, opts, "You are a memory consolidation agent.", []provider.Message{
+				{Role: "user", Content: prompt},
+			}, []provider.ToolDefinition{saveConsolidationTool})
+			if err != nil {
+				return err
+			}
+			for _, block := range resp.Content {
+				if block.Type == "tool_use" && block.Name == saveConsolidationTool.Name {
+					if err := json.Unmarshal(block.Input, &out); err != nil {
+						return fmt.Errorf("invalid %s input: %w", block.Name, err)
+					}
+					return nil
+				}
+			}
+			return fmt.Errorf("model did not call %s (stop reason %q)", saveConsolidationTool.Name, resp.StopReason)
+		}()
+		if err == nil || attempt >= consolidationAttempts {
 			break
 		}
+		slog.Warn("memory consolidation attempt failed", "attempt", attempt, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(consolidationBackoff * time.Duration(attempt)):
+		}
 	}
-	text = strings.TrimSpace(text)
-	if text == "" {
-		slog.Warn("memory consolidation: empty response")
-		return
+	if err != nil {
+		return err
 	}
 
-	// Strip possible markdown fences
-	if strings.HasPrefix(text, "```") {
-		if _, after, ok := strings.Cut(text, "\n"); ok {
-			text = after
+	res.HistoryEntry, res.Changes = out.HistoryEntry, out.MemoryChanges
+	// Apply the changes first: a failure queues the batch for a retry, which
+	// must not find its summary already journaled. Once they are applied the
+	// batch is done, so a journal error is only logged.
+	if !out.MemoryChanges.Empty() {
+		n, err := m.ApplyChanges(out.MemoryChanges, ReasonConsolidation, batch.SessionKey)
+		if err != nil {
+			return err
 		}
-		text = strings.TrimSuffix(text, "```")
-		text = strings.TrimSpace(text)
+		res.Applied += n
 	}
+	if out.HistoryEntry != "" {
+		if err := m.AppendJournal(batch.Messages[0].Timestamp, out.HistoryEntry); err != nil {
+			slog.Warn("failed to journal consolidated messages", "session", batch.SessionKey, "err", err)
+		}
+	}
+	return nil
+}
 
-	var result struct {
-		HistoryEntry string `json:"history_entry"`
-		MemoryUpdate string `json:"memory_update"`
+func (m *MemoryStore) markConsolidated(key string, t time.Time) {
+	if m.consolidated == nil {
+		m.consolidated = make(map[string]time.Time)
 	}
-	if err := json.Unmarshal([]byte(text), &result); err != nil {
-		preview := text
-		if len(preview) > 200 {
-			preview = preview[:200]
-		}
-		slog.Warn("memory consolidation: invalid JSON", "err", err, "text", preview)
-		return
+	if t.After(m.consolidated[key]) {
+		m.consolidated[key] = t
 	}
-	if result.HistoryEntry != "" {
-		_ = m.AppendHistory(result.HistoryEntry)
+}
+
+func (m *MemoryStore) pendingDir() string {
+	return filepath.Join(m.workspace, "memory", "pending")
+}
+
+func (m *MemoryStore) queueBatch(batch consolidationBatch) error {
+	if err := os.MkdirAll(m.pendingDir(), 0755); err != nil {
+		return err
 	}
-	if result.MemoryUpdate != "" && result.MemoryUpdate != currentMemory {
-		_ = m.WriteMemory(result.MemoryUpdate)
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
 	}
-	session.LastConsolidated = end
-	slog.Info("memory consolidation done", "messages", len(session.Messages), "last_consolidated", session.LastConsolidated)
+	name := fmt.Sprintf("%d.json", time.Now().UnixNano())
+	return writeFileAtomic(filepath.Join(m.pendingDir(), name), data, 0644)
+}
+
+// replayPending consolidates batches queued by earlier failures, oldest first,
+// and returns how many succeeded. Batches that fail again stay queued.
+func (m *MemoryStore) replayPending(ctx context.Context, claude ChatClient) int {
+	files, _ := filepath.Glob(filepath.Join(m.pendingDir(), "*.json"))
+	n := 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var batch consolidationBatch
+		if err := json.Unmarshal(data, &batch); err != nil || len(batch.Messages) == 0 {
+			slog.Warn("dropping unreadable consolidation batch", "path", path, "err", err)
+			os.Remove(path)
+			continue
+		}
+		var res ConsolidationResult
+		if err := m.consolidateBatch(ctx, claude, batch, &res); err != nil {
+			slog.Warn("queued memory consolidation failed again", "path", path, "err", err)
+			return n // the model is likely still unavailable; try again next run
+		}
+		os.Remove(path)
+		n++
+	}
+	return n
 }
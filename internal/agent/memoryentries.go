@@ -0,0 +1,567 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// defaultCategory holds entries remembered without a category.
+const defaultCategory = "general"
+
+// MemoryEntry is one structured long-term fact. MEMORY.md is rendered from
+// the entries and never edited by the model directly.
+type MemoryEntry struct {
+	ID       int       `json:"id"`
+	Category string    `json:"category"`
+	Content  string    `json:"content"`
+	Session  string    `json:"session,omitempty"` // session that added or last changed it
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// MemoryChanges are edits to the memory entries, as proposed by consolidation.
+type MemoryChanges struct {
+	Add    []MemoryChange `json:"add,omitempty"`
+	Update []MemoryChange `json:"update,omitempty"`
+	Remove []int          `json:"remove,omitempty"`
+}
+
+// MemoryChange adds (ID 0) or rewrites an entry.
+type MemoryChange struct {
+	ID       int    `json:"id,omitempty"`
+	Category string `json:"category,omitempty"`
+	Content  string `json:"content"`
+}
+
+// Empty reports whether c changes nothing.
+func (c MemoryChanges) Empty() bool {
+	return len(c.Add) == 0 && len(c.Update) == 0 && len(c.Remove) == 0
+}
+
+// memoryFile is the on-disk form of workspace/memory/entries.json.
+type memoryFile struct {
+	NextID  int           `json:"nextId"`
+	Entries []MemoryEntry `json:"entries"`
+}
+
+func (m *MemoryStore) entriesPath() string {
+	return filepath.Join(m.workspace, "memory", "entries.json")
+}
+
+// loadEntries reads the entries, importing MEMORY.md when it was edited by
+// hand (or predates structured memory) so no fact is lost. The caller holds m.mu.
+func (m *MemoryStore) loadEntries() (*memoryFile, error) {
+	mf := &memoryFile{NextID: 1}
+	data, err := os.ReadFile(m.entriesPath())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, mf); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", m.entriesPath(), err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	text, err := os.ReadFile(filepath.Join(m.workspace, "MEMORY.md"))
+	if err != nil || string(text) == renderMemory(mf.Entries) {
+		return mf, nil
+	}
+	mf.sync(parseMemory(string(text)), ReasonManual)
+	if err := m.saveEntries(mf, Revision{Reason: ReasonManual, Note: "edited MEMORY.md"}); err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
+// saveEntries persists the entries, records them as a new revision and
+// re-renders MEMORY.md. The caller holds m.mu.
+func (m *MemoryStore) saveEntries(mf *memoryFile, rev Revision) error {
+	if err := os.MkdirAll(filepath.Dir(m.entriesPath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(m.entriesPath(), data, 0644); err != nil {
+		return err
+	}
+	if err := m.snapshot(mf, rev); err != nil {
+		return fmt.Errorf("recording memory revision: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(m.workspace, "MEMORY.md"), []byte(renderMemory(mf.Entries)), 0644)
+}
+
+// Entries returns the memory entries, optionally limited to one category.
+func (m *MemoryStore) Entries(category string) ([]MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
+	if err != nil {
+		return nil, err
+	}
+	if category == "" {
+		return mf.Entries, nil
+	}
+	var out []MemoryEntry
+	for _, e := range mf.Entries {
+		if strings.EqualFold(e.Category, category) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Remember adds a fact and returns its entry.
+func (m *MemoryStore) Remember(category, content, session string) (MemoryEntry, error) {
+	content = normalizeFact(content)
+	if content == "" {
+		return MemoryEntry{}, fmt.Errorf("content is empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
+	if err != nil {
+		return MemoryEntry{}, err
+	}
+	e := mf.add(category, content, session, time.Now().UTC())
+	return e, m.saveEntries(mf, Revision{Reason: ReasonTool, Session: session, Note: fmt.Sprintf("remember #%d", e.ID)})
+}
+
+// Forget removes the entry with the given ID and returns it.
+func (m *MemoryStore) Forget(id int, session string) (MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
+	if err != nil {
+		return MemoryEntry{}, err
+	}
+	for i, e := range mf.Entries {
+		if e.ID == id {
+			mf.Entries = append(mf.Entries[:i], mf.Entries[i+1:]...)
+			return e, m.saveEntries(mf, Revision{Reason: ReasonTool, Session: session, Note: fmt.Sprintf("forget #%d", id)})
+		}
+	}
+	return MemoryEntry{}, fmt.Errorf("no memory entry #%d", id)
+}
+
+// ApplyChanges applies proposed edits and returns how many took effect.
+// Updates and removals naming unknown IDs are skipped.
+func (m *MemoryStore) ApplyChanges(c MemoryChanges, reason, session string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	applied := 0
+	remove := make(map[int]bool)
+	for _, id := range c.Remove {
+		remove[id] = true
+	}
+	kept := mf.Entries[:0]
+	for _, e := range mf.Entries {
+		if remove[e.ID] {
+			applied++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	mf.Entries = kept
+	for _, u := range c.Update {
+		for i := range mf.Entries {
+			e := &mf.Entries[i]
+			content := normalizeFact(u.Content)
+			if e.ID != u.ID || content == "" {
+				continue
+			}
+			if u.Category != "" {
+				e.Category = normalizeCategory(u.Category)
+			}
+			e.Content, e.Session, e.Updated = content, session, now
+			applied++
+		}
+	}
+	for _, a := range c.Add {
+		if content := normalizeFact(a.Content); content != "" {
+			mf.add(a.Category, content, session, now)
+			applied++
+		}
+	}
+	if applied == 0 {
+		return 0, nil
+	}
+	return applied, m.saveEntries(mf, Revision{Reason: reason, Session: session})
+}
+
+func (mf *memoryFile) add(category, content, session string, now time.Time) MemoryEntry {
+	if mf.NextID < 1 {
+		mf.NextID = 1
+	}
+	e := MemoryEntry{
+		ID:       mf.NextID,
+		Category: normalizeCategory(category),
+		Content:  content,
+		Session:  session,
+		Created:  now,
+		Updated:  now,
+	}
+	mf.NextID++
+	mf.Entries = append(mf.Entries, e)
+	return e
+}
+
+// sync makes the entries match facts parsed from MEMORY.md, keeping the
+// metadata of entries whose ID and text are unchanged.
+func (mf *memoryFile) sync(facts []MemoryChange, source string) {
+	now := time.Now().UTC()
+	byID := make(map[int]MemoryEntry, len(mf.Entries))
+	for _, e := range mf.Entries {
+		byID[e.ID] = e
+	}
+	old := mf.Entries
+	mf.Entries = nil
+	seen := make(map[int]bool)
+	for _, f := range facts {
+		e, ok := byID[f.ID]
+		if !ok || seen[f.ID] {
+			mf.add(f.Category, f.Content, source, now)
+			continue
+		}
+		seen[f.ID] = true
+		if e.Content != f.Content || e.Category != normalizeCategory(f.Category) {
+			e.Content, e.Category, e.Session, e.Updated = f.Content, normalizeCategory(f.Category), source, now
+		}
+		mf.Entries = append(mf.Entries, e)
+	}
+	for _, e := range old {
+		if e.ID >= mf.NextID {
+			mf.NextID = e.ID + 1
+		}
+	}
+}
+
+// renderMemory formats entries as MEMORY.md: one heading per category and one
+// bullet per fact, tagged with its ID so it can be forgotten or updated.
+func renderMemory(entries []MemoryEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var cats []string
+	byCat := make(map[string][]MemoryEntry)
+	for _, e := range entries {
+		if _, ok := byCat[e.Category]; !ok {
+			cats = append(cats, e.Category)
+		}
+		byCat[e.Category] = append(byCat[e.Category], e)
+	}
+	sort.Strings(cats)
+	var sb strings.Builder
+	for i, c := range cats {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n", c)
+		for _, e := range byCat[c] {
+			fmt.Fprintf(&sb, "- %s (#%d)\n", e.Content, e.ID)
+		}
+	}
+	return sb.String()
+}
+
+var reFactID = regexp.MustCompile(`\s*\(#(\d+)\)\s*$`)
+
+// parseMemory reads facts back from MEMORY.md. Headings set the category,
+// bullets are facts, and other paragraphs become one fact each.
+func parseMemory(text string) []MemoryChange {
+	var facts []MemoryChange
+	category := defaultCategory
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			facts = append(facts, parseFact(category, strings.Join(para, " ")))
+			para = nil
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			category = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flush()
+			para = []string{trimmed[2:]}
+		default:
+			para = append(para, trimmed) // wrapped bullet or plain paragraph
+		}
+	}
+	flush()
+	out := facts[:0]
+	for _, f := range facts {
+		if f.Content != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func parseFact(category, text string) MemoryChange {
+	f := MemoryChange{Category: category}
+	if m := reFactID.FindStringSubmatchIndex(text); m != nil {
+		f.ID, _ = strconv.Atoi(text[m[2]:m[3]])
+		text = text[:m[0]]
+	}
+	f.Content = normalizeFact(text)
+	return f
+}
+
+func normalizeFact(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func normalizeCategory(s string) string {
+	s = strings.ToLower(normalizeFact(s))
+	if s == "" {
+		return defaultCategory
+	}
+	return s
+}
+
+// FormatMemoryEntries lists entries for memory_list.
+func FormatMemoryEntries(entries []MemoryEntry, loc *time.Location) string {
+	if len(entries) == 0 {
+		return "No memory entries."
+	}
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "#%d [%s] %s (updated %s", e.ID, e.Category, e.Content, e.Updated.In(loc).Format("2006-01-02"))
+		if e.Session != "" {
+			sb.WriteString(", " + e.Session)
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// registerMemoryTools adds the tools that read and edit the caller's memory.
+func (l *Loop) registerMemoryTools() {
+	memory := func(ctx context.Context) *MemoryStore {
+		return l.memoryFor(tools.CallerFrom(ctx).Workspace)
+	}
+	l.reg.Register(tools.NewMemorySearchTool(func(ctx context.Context, query, source string, limit int) (string, error) {
+		return FormatMemoryHits(memory(ctx).Recall(query, source, limit)), nil
+	}))
+	l.reg.Register(tools.NewMemoryRememberTool(func(ctx context.Context, category, content string) (string, error) {
+		e, err := memory(ctx).Remember(category, content, tools.CallerFrom(ctx).SessionKey)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Remembered #%d [%s] %s", e.ID, e.Category, e.Content), nil
+	}))
+	l.reg.Register(tools.NewMemoryForgetTool(func(ctx context.Context, id int) (string, error) {
+		e, err := memory(ctx).Forget(id, tools.CallerFrom(ctx).SessionKey)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Forgot #%d: %s", e.ID, e.Content), nil
+	}))
+	l.reg.Register(tools.NewJournalReadTool(func(ctx context.Context, kind, from, to string) (string, error) {
+		loc := l.cfg.Location()
+		day := func(s string, def time.Time) (time.Time, error) {
+			if s == "" {
+				return def, nil
+			}
+			t, err := time.ParseInLocation("2006-01-02", s, loc)
+			if err != nil {
+				return t, fmt.Errorf("invalid date %q (want YYYY-MM-DD)", s)
+			}
+			return t, nil
+		}
+		start, err := day(from, time.Now().In(loc))
+		if err != nil {
+			return "", err
+		}
+		end, err := day(to, start)
+		if err != nil {
+			return "", err
+		}
+		text := memory(ctx).ReadJournals(kind, start, end)
+		if text == "" {
+			return fmt.Sprintf("No %s journal between %s and %s.", kind, start.Format("2006-01-02"), end.Format("2006-01-02")), nil
+		}
+		return text, nil
+	}))
+	l.reg.Register(tools.NewMemoryListTool(func(ctx context.Context, category string) (string, error) {
+		entries, err := memory(ctx).Entries(category)
+		if err != nil {
+			return "", err
+		}
+		return FormatMemoryEntries(entries, l.cfg.Location()), nil
+	}))
+}
+
+// defaultMemoryMaxSize is the MEMORY.md size above which Compact reorganizes it.
+const defaultMemoryMaxSize = 8192
+
+// saveMemoryTool is the tool the model is forced to call when compacting.
+var saveMemoryTool = provider.ToolDefinition{
+	Name:        "save_memory",
+	Description: "Save the reorganized long-term memory.",
+	InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "entries": {"type": "array", "items": {"type": "object", "properties": {
+      "category": {"type": "string"},
+      "content":  {"type": "string"},
+      "ids":      {"type": "array", "items": {"type": "integer"}, "description": "IDs of the current entries this one replaces."}
+    }, "required": ["category", "content", "ids"]}}
+  },
+  "required": ["entries"]
+}`),
+}
+
+// CompactResult reports what Compact did.
+type CompactResult struct {
+	Skipped      bool // under the size limit and not forced
+	SizeBefore   int
+	SizeAfter    int
+	EntriesAfter int
+	Revision     int
+}
+
+// Compact asks the model to deduplicate and reorganize the memory entries
+// when MEMORY.md is larger than maxSize bytes (0 means the default), or
+// always when force is set. Merged entries keep the oldest ID and creation
+// time of the entries they replace. Entries added or changed while the model
+// works are kept as they are.
+func (m *MemoryStore) Compact(ctx context.Context, claude ChatClient, maxSize int, force bool) (CompactResult, error) {
+	if maxSize <= 0 {
+		maxSize = defaultMemoryMaxSize
+	}
+	m.consolidating.Lock()
+	defer m.consolidating.Unlock()
+
+	entries, err := m.Entries("")
+	if err != nil {
+		return CompactResult{}, err
+	}
+	res := CompactResult{SizeBefore: len(renderMemory(entries))}
+	if len(entries) == 0 || (!force && res.SizeBefore <= maxSize) {
+		res.Skipped = true
+		res.SizeAfter, res.EntriesAfter = res.SizeBefore, len(entries)
+		return res, nil
+	}
+
+	prompt := fmt.Sprintf(`Reorganize this long-term memory and call save_memory with the result.
+Merge duplicates and near-duplicates, drop facts that later entries contradict or make obsolete,
+keep every other fact, and group facts into a few short lowercase categories. List in "ids" the
+current entries each new entry replaces. Aim for under %d bytes in total.
+
+## Current Entries
+%s`, maxSize*3/4, FormatMemoryEntries(entries, time.UTC))
+	resp, err := claude.ChatWith(ctx, provider.ChatOptions{ToolChoice: &provider.ToolChoice{Type: "tool", Name: saveMemoryTool.Name}},
+		"You maintain a personal assistant's long-term memory.",
+		[]provider.Message{{Role: "user", Content: prompt}}, []provider.ToolDefinition{saveMemoryTool})
+	if err != nil {
+		return res, err
+	}
+	var out struct {
+		Entries []struct {
+			Category string `json:"category"`
+			Content  string `json:"content"`
+			IDs      []int  `json:"ids"`
+		} `json:"entries"`
+	}
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" && block.Name == saveMemoryTool.Name {
+			if err := json.Unmarshal(block.Input, &out); err != nil {
+				return res, fmt.Errorf("invalid %s input: %w", block.Name, err)
+			}
+		}
+	}
+	if len(out.Entries) == 0 {
+		return res, fmt.Errorf("model returned no entries; memory left unchanged")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.loadEntries()
+	if err != nil {
+		return res, err
+	}
+	byID := make(map[int]MemoryEntry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	// A consolidation or memory_write may have run during the model call:
+	// entries it added or changed are kept, and ones it forgot stay gone.
+	present := make(map[int]bool, len(mf.Entries))
+	var kept []MemoryEntry
+	for _, e := range mf.Entries {
+		present[e.ID] = true
+		if old, ok := byID[e.ID]; !ok || old.Content != e.Content || old.Category != e.Category || !old.Updated.Equal(e.Updated) {
+			kept = append(kept, e)
+			delete(byID, e.ID)
+		}
+	}
+	now := time.Now().UTC()
+	used := make(map[int]bool)
+	var compacted []MemoryEntry
+	for _, o := range out.Entries {
+		content := normalizeFact(o.Content)
+		if content == "" {
+			continue
+		}
+		if len(o.IDs) > 0 && !slices.ContainsFunc(o.IDs, func(id int) bool { _, ok := byID[id]; return ok && present[id] }) {
+			continue // everything it replaces was forgotten or changed meanwhile
+		}
+		e := MemoryEntry{Category: normalizeCategory(o.Category), Content: content, Session: "compact", Created: now, Updated: now}
+		for _, id := range o.IDs {
+			old, ok := byID[id]
+			if !ok || used[id] {
+				continue
+			}
+			used[id] = true
+			if e.ID == 0 || id < e.ID {
+				e.ID = id
+			}
+			if old.Created.Before(e.Created) {
+				e.Created = old.Created
+			}
+		}
+		if e.ID == 0 {
+			e.ID = mf.NextID
+			mf.NextID++
+		}
+		compacted = append(compacted, e)
+	}
+	mf.Entries = append(compacted, kept...)
+	if err := m.saveEntries(mf, Revision{Reason: ReasonConsolidation, Note: "compact"}); err != nil {
+		return res, err
+	}
+	res.SizeAfter, res.EntriesAfter = len(renderMemory(mf.Entries)), len(mf.Entries)
+	if revs, err := m.readRevisions(); err == nil && len(revs) > 0 {
+		res.Revision = revs[len(revs)-1].Rev
+	}
+	return res, nil
+}
@@ -0,0 +1,311 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Reasons recorded with each memory revision.
+const (
+	ReasonConsolidation = "consolidation"
+	ReasonTool          = "tool"
+	ReasonManual        = "manual"
+)
+
+// Revision describes one saved version of the memory entries.
+type Revision struct {
+	Rev     int       `json:"rev"`
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Session string    `json:"session,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	Added   int       `json:"added"`   // MEMORY.md lines added
+	Removed int       `json:"removed"` // MEMORY.md lines removed
+}
+
+func (m *MemoryStore) versionsDir() string {
+	return filepath.Join(m.workspace, "memory", "versions")
+}
+
+func (m *MemoryStore) snapshotPath(rev int) string {
+	return filepath.Join(m.versionsDir(), fmt.Sprintf("%06d.json", rev))
+}
+
+// snapshot stores mf as a new revision. The caller holds m.mu.
+func (m *MemoryStore) snapshot(mf *memoryFile, rev Revision) error {
+	revs, err := m.readRevisions()
+	if err != nil {
+		return err
+	}
+	prev := &memoryFile{}
+	if len(revs) > 0 {
+		last := revs[len(revs)-1].Rev
+		rev.Rev = last + 1
+		if p, err := m.readSnapshot(last); err == nil {
+			prev = p
+		}
+	} else {
+		rev.Rev = 1
+	}
+	rev.Time = time.Now().UTC()
+	for _, d := range diffLines(renderMemory(prev.Entries), renderMemory(mf.Entries)) {
+		switch d.op {
+		case '+':
+			rev.Added++
+		case '-':
+			rev.Removed++
+		}
+	}
+
+	if err := os.MkdirAll(m.versionsDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(m.snapshotPath(rev.Rev), data, 0644); err != nil {
+		return err
+	}
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(m.versionsDir(), "log.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func (m *MemoryStore) readRevisions() ([]Revision, error) {
+	f, err := os.Open(filepath.Join(m.versionsDir(), "log.jsonl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var revs []Revision
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r Revision
+		if json.Unmarshal(sc.Bytes(), &r) == nil && r.Rev > 0 {
+			revs = append(revs, r)
+		}
+	}
+	return revs, sc.Err()
+}
+
+// Revisions returns the memory revisions, oldest first.
+func (m *MemoryStore) Revisions() ([]Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.readRevisions()
+}
+
+func (m *MemoryStore) readSnapshot(rev int) (*memoryFile, error) {
+	if rev == 0 {
+		return &memoryFile{NextID: 1}, nil // the empty memory before the first revision
+	}
+	data, err := os.ReadFile(m.snapshotPath(rev))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no memory revision %d", rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mf := &memoryFile{}
+	if err := json.Unmarshal(data, mf); err != nil {
+		return nil, fmt.Errorf("parsing revision %d: %w", rev, err)
+	}
+	return mf, nil
+}
+
+// Snapshot returns MEMORY.md as it was at rev.
+func (m *MemoryStore) Snapshot(rev int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mf, err := m.readSnapshot(rev)
+	if err != nil {
+		return "", err
+	}
+	return renderMemory(mf.Entries), nil
+}
+
+// Diff returns a line diff of MEMORY.md between two revisions.
+func (m *MemoryStore) Diff(from, to int) (string, error) {
+	a, err := m.Snapshot(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := m.Snapshot(to)
+	if err != nil {
+		return "", err
+	}
+	return formatDiff(diffLines(a, b), 2), nil
+}
+
+// Rollback restores the entries saved at rev, recording the restore as a new revision.
+func (m *MemoryStore) Rollback(rev int, session string) (Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	target, err := m.readSnapshot(rev)
+	if err != nil {
+		return Revision{}, err
+	}
+	cur, err := m.loadEntries()
+	if err != nil {
+		return Revision{}, err
+	}
+	// Keep IDs increasing so forgotten IDs are never reused.
+	if cur.NextID > target.NextID {
+		target.NextID = cur.NextID
+	}
+	if err := m.saveEntries(target, Revision{Reason: ReasonManual, Session: session, Note: fmt.Sprintf("rollback to r%d", rev)}); err != nil {
+		return Revision{}, err
+	}
+	revs, err := m.readRevisions()
+	if err != nil || len(revs) == 0 {
+		return Revision{}, err
+	}
+	return revs[len(revs)-1], nil
+}
+
+// FormatRevisions lists revisions newest first.
+func FormatRevisions(revs []Revision, loc *time.Location) string {
+	if len(revs) == 0 {
+		return "No memory changes recorded yet."
+	}
+	var sb strings.Builder
+	for i := len(revs) - 1; i >= 0; i-- {
+		r := revs[i]
+		fmt.Fprintf(&sb, "r%-4d %s  %-13s +%d -%d", r.Rev, r.Time.In(loc).Format("2006-01-02 15:04"), r.Reason, r.Added, r.Removed)
+		if r.Session != "" {
+			sb.WriteString("  " + r.Session)
+		}
+		if r.Note != "" {
+			sb.WriteString("  (" + r.Note + ")")
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+type diffLine struct {
+	op   byte // ' ', '+' or '-'
+	text string
+}
+
+// diffLines computes a line diff of a and b from their longest common subsequence.
+func diffLines(a, b string) []diffLine {
+	x, y := splitLines(a), splitLines(b)
+	n, m := len(x), len(y)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out []diffLine
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && x[i] == y[j]:
+			out = append(out, diffLine{' ', x[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, diffLine{'-', x[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', y[j]})
+			j++
+		}
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// formatDiff renders changed lines with up to context unchanged lines around them.
+func formatDiff(lines []diffLine, context int) string {
+	show := make([]bool, len(lines))
+	changed := false
+	for i, d := range lines {
+		if d.op == ' ' {
+			continue
+		}
+		changed = true
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			show[k] = true
+		}
+	}
+	if !changed {
+		return "No changes."
+	}
+	var sb strings.Builder
+	gap := false
+	for i, d := range lines {
+		if !show[i] {
+			gap = true
+			continue
+		}
+		if gap && sb.Len() > 0 {
+			sb.WriteString("…\n")
+		}
+		gap = false
+		fmt.Fprintf(&sb, "%c %s\n", d.op, d.text)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// registerMemoryCommand adds /memory, which shows recent memory changes.
+func (l *Loop) registerMemoryCommand() {
+	l.cmds.Register(Command{
+		Name:        "memory",
+		Description: "Show recent changes to long-term memory",
+		Handler: func(ctx context.Context, req CommandRequest) (string, error) {
+			memory := l.tenantFor(ctx, req.ChatID).memory
+			revs, err := memory.Revisions()
+			if err != nil {
+				return "", err
+			}
+			if len(revs) == 0 {
+				return "🧠 " + FormatRevisions(nil, nil), nil
+			}
+			last := revs[len(revs)-1].Rev
+			diff, err := memory.Diff(last-1, last)
+			if err != nil {
+				diff = err.Error()
+			}
+			return fmt.Sprintf("🧠 Recent memory changes:\n%s\n\nLatest (r%d):\n%s",
+				FormatRevisions(revs[max(0, len(revs)-5):], l.cfg.Location()), last, diff), nil
+		},
+	})
+}
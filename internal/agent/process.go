@@ -0,0 +1,51 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// processNotifyLines is how much of a finished process's output the exit
+// notification shows.
+const processNotifyLines = 10
+
+func (l *Loop) registerProcessTools(sandbox *tools.Sandbox, policy *tools.CommandPolicy) {
+	l.procs = tools.NewProcessManager(sandbox)
+	l.procs.Policy = policy
+	l.procs.OnExit = l.notifyProcessExit
+	l.reg.Register(tools.NewProcessStartTool(l.procs))
+	l.reg.Register(tools.NewProcessOutputTool(l.procs))
+	l.reg.Register(tools.NewProcessListTool(l.procs))
+	l.reg.Register(tools.NewProcessKillTool(l.procs))
+}
+
+// notifyProcessExit tells the chat that started a background process that it
+// finished, with the last lines of its output. Processes stopped on request
+// are not announced.
+func (l *Loop) notifyProcessExit(info tools.ProcessInfo, output string) {
+	slog.Info("background process exited", "id", info.ID, "session", info.SessionKey, "code", info.ExitCode, "killed", info.Killed)
+	if info.Killed || l.sendFn == nil || info.ChatID == "" {
+		return
+	}
+	icon := "✅"
+	if info.ExitCode != 0 {
+		icon = "❌"
+	}
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > processNotifyLines {
+		lines = lines[len(lines)-processNotifyLines:]
+	}
+	msg := fmt.Sprintf("%s Background process %s exited with code %d after %s: %s",
+		icon, info.ID, info.ExitCode, info.Ended.Sub(info.Started).Round(time.Second), truncateRunes(info.Command, 80))
+	if tail := strings.TrimSpace(strings.Join(lines, "\n")); tail != "" {
+		msg += "\n\n" + tail
+	}
+	if err := l.sendFn(info.ChatID, msg); err != nil {
+		slog.Error("process exit notification failed", "id", info.ID, "err", err)
+	}
+}
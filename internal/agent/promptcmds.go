@@ -0,0 +1,216 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PromptCommand is a user-defined slash command loaded from workspace/commands/<name>.md.
+// Invoking it expands Template with the given arguments and sends the result to the model.
+type PromptCommand struct {
+	Name         string
+	Description  string
+	Arguments    []string // named arguments, bound to positional args in order
+	AllowedTools []string // nil means all tools
+	Template     string
+	Path         string
+}
+
+// Expand substitutes $ARGUMENTS, $1..$9 and $<name> for each declared argument.
+// The last declared argument receives any remaining arguments.
+func (p PromptCommand) Expand(args []string, rawArgs string) string {
+	vars := map[string]string{"ARGUMENTS": rawArgs}
+	for i := 0; i < 9; i++ {
+		v := ""
+		if i < len(args) {
+			v = args[i]
+		}
+		vars[fmt.Sprint(i+1)] = v
+	}
+	for i, name := range p.Arguments {
+		switch {
+		case i >= len(args):
+			vars[name] = ""
+		case i == len(p.Arguments)-1:
+			vars[name] = strings.Join(args[i:], " ")
+		default:
+			vars[name] = args[i]
+		}
+	}
+	return rePromptVar.ReplaceAllStringFunc(p.Template, func(m string) string {
+		name := strings.Trim(m[1:], "{}")
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+var rePromptVar = regexp.MustCompile(`\$(\{[A-Za-z0-9_]+\}|[A-Za-z0-9_]+)`)
+
+// reCommandName strips characters that cannot appear in a command name.
+var reCommandName = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// PromptCommands keeps the command registry in sync with a commands directory.
+type PromptCommands struct {
+	dir  string
+	cmds *CommandRegistry
+	run  func(ctx context.Context, req CommandRequest, p PromptCommand) (string, error)
+
+	mu     sync.Mutex
+	loaded map[string]string // command name -> signature (path, size, mtime)
+}
+
+// NewPromptCommands creates a loader for dir. run executes an expanded prompt command.
+func NewPromptCommands(dir string, cmds *CommandRegistry, run func(ctx context.Context, req CommandRequest, p PromptCommand) (string, error)) *PromptCommands {
+	return &PromptCommands{dir: dir, cmds: cmds, run: run, loaded: make(map[string]string)}
+}
+
+// Reload rescans the directory, registering new or changed commands and
+// removing deleted ones. It is cheap when nothing changed.
+func (pc *PromptCommands) Reload() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	entries, _ := os.ReadDir(pc.dir)
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			continue
+		}
+		name := commandName(e.Name())
+		if name == "" || seen[name] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(pc.dir, e.Name())
+		sig := fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano())
+		if _, ours := pc.loaded[name]; !ours {
+			if _, taken := pc.cmds.Lookup(name); taken {
+				slog.Warn("prompt command shadows a built-in command, skipping", "name", name, "path", path)
+				continue
+			}
+		}
+		seen[name] = true
+		if pc.loaded[name] == sig {
+			continue
+		}
+		p, err := loadPromptCommand(name, path)
+		if err != nil {
+			slog.Warn("could not load prompt command", "path", path, "err", err)
+			delete(seen, name)
+			continue
+		}
+		pc.register(p)
+		pc.loaded[name] = sig
+		slog.Info("prompt command loaded", "name", name)
+	}
+	for name := range pc.loaded {
+		if !seen[name] {
+			pc.cmds.Unregister(name)
+			delete(pc.loaded, name)
+			slog.Info("prompt command removed", "name", name)
+		}
+	}
+}
+
+// Watch reloads the directory every interval until ctx is cancelled, so
+// channels see new commands (and refresh their menus) without a message arriving.
+func (pc *PromptCommands) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pc.Reload()
+		}
+	}
+}
+
+// Names returns the names of the loaded prompt commands.
+func (pc *PromptCommands) Names() []string {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	names := make([]string, 0, len(pc.loaded))
+	for name := range pc.loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (pc *PromptCommands) register(p PromptCommand) {
+	usage := ""
+	if len(p.Arguments) > 0 {
+		usage = "<" + strings.Join(p.Arguments, "> <") + ">"
+	}
+	pc.cmds.Register(Command{
+		Name:        p.Name,
+		Usage:       usage,
+		Description: p.Description,
+		Handler: func(ctx context.Context, req CommandRequest) (string, error) {
+			return pc.run(ctx, req, p)
+		},
+	})
+}
+
+func loadPromptCommand(name, path string) (PromptCommand, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PromptCommand{}, err
+	}
+	fm, body := parseFrontmatter(string(data))
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return PromptCommand{}, fmt.Errorf("empty prompt template")
+	}
+	p := PromptCommand{
+		Name:         name,
+		Description:  fm.get("description"),
+		Arguments:    fm.list("arguments", "argument-hint", "args"),
+		AllowedTools: fm.list("allowed-tools", "allowed_tools", "tools"),
+		Template:     body,
+		Path:         path,
+	}
+	for i, a := range p.Arguments {
+		p.Arguments[i] = strings.Trim(a, "<>[]$")
+	}
+	if p.Description == "" {
+		first, _, _ := strings.Cut(body, "\n")
+		p.Description = truncateRunes(strings.TrimLeft(first, "# "), 100)
+	}
+	return p, nil
+}
+
+// commandName derives a command name from a file name: "Draft-Standup.md" -> "draft_standup".
+func commandName(file string) string {
+	base := strings.ToLower(strings.TrimSuffix(file, filepath.Ext(file)))
+	name := strings.Trim(reCommandName.ReplaceAllString(base, "_"), "_")
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// truncateRunes shortens s to at most n runes, adding an ellipsis when cut.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
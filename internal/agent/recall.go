@@ -0,0 +1,225 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/search"
+)
+
+// Memory sources searched by Recall.
+const (
+	SourceHistory = "history" // journal entries and rollups
+	SourceMemory  = "memory"  // MEMORY.md sections
+)
+
+// recallLimit and recallBytes bound the history entries put in the system prompt.
+const (
+	recallLimit = 5
+	recallBytes = 3000
+)
+
+// MemoryHit is one retrievable piece of memory: a journal entry or rollup,
+// or a MEMORY.md section.
+type MemoryHit struct {
+	Source string
+	File   string    // relative to the workspace, e.g. memory/2026-10-18.md
+	Date   time.Time // first day the file covers; zero for MEMORY.md
+	Index  int       // position within its file
+	Text   string
+}
+
+// memoryIndex is a BM25 index over a workspace's memory files, rebuilt
+// whenever one of them changes on disk.
+type memoryIndex struct {
+	mu      sync.Mutex
+	stamp   string
+	ix      *search.Index
+	entries map[string]MemoryHit
+	history []MemoryHit
+}
+
+// reHistoryStart matches the timestamp that begins each consolidated history entry.
+var reHistoryStart = regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2}`)
+
+// splitHistory splits a journal (or legacy HISTORY.md) into entries: paragraphs separated by blank
+// lines, also breaking where a line starts with a "[YYYY-MM-DD" timestamp.
+func splitHistory(content string) []string {
+	var entries []string
+	var cur []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(cur, "\n")); text != "" {
+			entries = append(entries, text)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" || reHistoryStart.MatchString(line) {
+			flush()
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return entries
+}
+
+// splitSections splits MEMORY.md at Markdown headings, keeping each heading
+// with its body. Text without headings becomes one section per paragraph.
+func splitSections(content string) []string {
+	if !strings.Contains("\n"+content, "\n#") {
+		return splitHistory(content)
+	}
+	var sections []string
+	var cur []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(cur, "\n")); text != "" {
+			sections = append(sections, text)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "#") {
+			flush()
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return sections
+}
+
+func fileStamp(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d", fi.Size(), fi.ModTime().UnixNano())
+}
+
+// index returns the up-to-date memory index for the store.
+func (m *MemoryStore) index() *memoryIndex {
+	mi := &m.recall
+	journals := m.journals("")
+	stamps := []string{fileStamp(filepath.Join(m.workspace, "MEMORY.md"))}
+	for _, f := range journals {
+		stamps = append(stamps, f.path+"="+fileStamp(f.path))
+	}
+	stamp := strings.Join(stamps, " ")
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+	if mi.ix != nil && mi.stamp == stamp {
+		return mi
+	}
+	mi.stamp = stamp
+	mi.ix = search.New()
+	mi.entries = make(map[string]MemoryHit)
+	mi.history = nil
+	add := func(hit MemoryHit, texts []string) {
+		for i, text := range texts {
+			e := hit
+			e.Index, e.Text = i, text
+			id := fmt.Sprintf("%s:%d", e.File, i)
+			mi.entries[id] = e
+			mi.ix.Add(id, text)
+		}
+	}
+	for _, f := range journals {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		rel, _ := filepath.Rel(m.workspace, f.path)
+		texts := splitHistory(string(data))
+		add(MemoryHit{Source: SourceHistory, File: rel, Date: f.start}, texts)
+		if f.kind == JournalDaily {
+			for i, text := range texts {
+				mi.history = append(mi.history, MemoryHit{Source: SourceHistory, File: rel, Date: f.start, Index: i, Text: text})
+			}
+		}
+	}
+	add(MemoryHit{Source: SourceMemory, File: "MEMORY.md"}, splitSections(m.ReadMemory()))
+	return mi
+}
+
+// Recall returns the memory entries most relevant to query, best first.
+// source restricts the search to SourceHistory or SourceMemory; empty searches both.
+func (m *MemoryStore) Recall(query, source string, limit int) []MemoryHit {
+	mi := m.index()
+	mi.mu.Lock()
+	defer mi.mu.Unlock()
+	keep := func(id string) bool { return source == "" || mi.entries[id].Source == source }
+	var out []MemoryHit
+	for _, h := range mi.ix.Search(query, limit, keep) {
+		out = append(out, mi.entries[h.ID])
+	}
+	return out
+}
+
+// RelevantHistory selects older journal entries to show in the system prompt
+// for userMsg: the best matches, or the latest entries when nothing matches,
+// listed in chronological order and capped at recallBytes. Yesterday's and
+// today's journals are left out since the prompt includes them in full.
+func (m *MemoryStore) RelevantHistory(userMsg string, now time.Time) string {
+	recent := startOfDay(now.In(m.location())).AddDate(0, 0, -1)
+	older := func(e MemoryHit) bool { return e.Date.Before(recent) }
+
+	var entries []MemoryHit
+	for _, e := range m.Recall(userMsg, SourceHistory, recallLimit+4) {
+		if older(e) && len(entries) < recallLimit {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		mi := m.index()
+		mi.mu.Lock()
+		for i := len(mi.history) - 1; i >= 0 && len(entries) < 2; i-- {
+			if older(mi.history[i]) {
+				entries = append(entries, mi.history[i])
+			}
+		}
+		mi.mu.Unlock()
+	}
+
+	var picked []MemoryHit
+	size := 0
+	for _, e := range entries {
+		if size+len(e.Text) > recallBytes && len(picked) > 0 {
+			break
+		}
+		picked = append(picked, e)
+		size += len(e.Text)
+	}
+	// Chronological order reads more naturally than rank order.
+	sort.Slice(picked, func(i, j int) bool {
+		if !picked[i].Date.Equal(picked[j].Date) {
+			return picked[i].Date.Before(picked[j].Date)
+		}
+		return picked[i].Index < picked[j].Index
+	})
+	texts := make([]string, len(picked))
+	for i, e := range picked {
+		texts[i] = tailText(recallBytes, e.Text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// FormatMemoryHits renders entries for the memory_search tool.
+func FormatMemoryHits(entries []MemoryHit) string {
+	if len(entries) == 0 {
+		return "No matching memory."
+	}
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "### %s #%d\n%s", e.File, e.Index+1, truncateRunes(e.Text, 1500))
+	}
+	return sb.String()
+}
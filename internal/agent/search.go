@@ -0,0 +1,289 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/search"
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// SearchOptions narrows a session search.
+type SearchOptions struct {
+	Query string
+	From  time.Time // inclusive; zero means unbounded
+	To    time.Time // exclusive; zero means unbounded
+	Role  string    // "user" or "assistant"; empty means both
+	Limit int
+	Allow func(s SearchResult) bool // optional visibility filter
+}
+
+// SearchResult is one matching message.
+type SearchResult struct {
+	SessionKey string
+	ChatID     string
+	UserID     string
+	Index      int
+	Role       string
+	Timestamp  time.Time
+	Snippet    string
+}
+
+type searchDoc struct {
+	key       string
+	index     int
+	role      string
+	timestamp time.Time
+	content   string
+}
+
+type indexedSession struct {
+	count  int       // messages indexed
+	lastTS time.Time // timestamp of the last indexed message
+	chatID string
+	userID string
+}
+
+// SessionSearch is a full-text index over every stored session message. It is
+// built from disk on first use and kept current through SessionManager.OnSave.
+type SessionSearch struct {
+	sm *SessionManager
+	ix *search.Index
+
+	mu       sync.Mutex
+	built    bool
+	docs     map[string]searchDoc
+	sessions map[string]*indexedSession
+}
+
+// NewSessionSearch creates a search index over sm's sessions.
+func NewSessionSearch(sm *SessionManager) *SessionSearch {
+	ss := &SessionSearch{
+		sm:       sm,
+		ix:       search.New(),
+		docs:     make(map[string]searchDoc),
+		sessions: make(map[string]*indexedSession),
+	}
+	sm.OnSave(ss.update)
+	return ss
+}
+
+func docID(key string, i int) string { return fmt.Sprintf("%s\x00%d", key, i) }
+
+// build indexes every session on disk. The caller holds ss.mu.
+func (ss *SessionSearch) build() {
+	if ss.built {
+		return
+	}
+	ss.built = true
+	infos, _ := ss.sm.List()
+	for _, info := range infos {
+		ss.index(ss.sm.Peek(info.Key))
+	}
+}
+
+// update is the OnSave hook: it indexes only messages added since the last
+// save, or re-indexes the session if earlier messages were removed.
+func (ss *SessionSearch) update(s *Session) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if !ss.built {
+		return // the first search reads everything from disk
+	}
+	ss.index(s)
+}
+
+// index brings the index up to date with s. The caller holds ss.mu.
+func (ss *SessionSearch) index(s *Session) {
+	st := ss.sessions[s.Key]
+	if st == nil {
+		st = &indexedSession{}
+		ss.sessions[s.Key] = st
+	}
+	st.chatID, st.userID = s.ChatID, s.UserID
+	intact := st.count <= len(s.Messages) &&
+		(st.count == 0 || s.Messages[st.count-1].Timestamp.Equal(st.lastTS))
+	if !intact {
+		ss.drop(s.Key, st.count)
+		st.count = 0
+	}
+	for i := st.count; i < len(s.Messages); i++ {
+		m := s.Messages[i]
+		id := docID(s.Key, i)
+		ss.docs[id] = searchDoc{key: s.Key, index: i, role: m.Role, timestamp: m.Timestamp, content: m.Content}
+		ss.ix.Add(id, m.Content)
+	}
+	st.count = len(s.Messages)
+	if st.count > 0 {
+		st.lastTS = s.Messages[st.count-1].Timestamp
+	}
+}
+
+func (ss *SessionSearch) drop(key string, count int) {
+	for i := 0; i < count; i++ {
+		id := docID(key, i)
+		ss.ix.Remove(id)
+		delete(ss.docs, id)
+	}
+}
+
+// Search returns messages matching opts, best match first.
+func (ss *SessionSearch) Search(opts SearchOptions) []SearchResult {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.build()
+
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	result := func(d searchDoc) SearchResult {
+		st := ss.sessions[d.key]
+		return SearchResult{
+			SessionKey: d.key, ChatID: st.chatID, UserID: st.userID,
+			Index: d.index, Role: d.role, Timestamp: d.timestamp,
+		}
+	}
+	keep := func(id string) bool {
+		d, ok := ss.docs[id]
+		switch {
+		case !ok:
+			return false
+		case opts.Role != "" && d.role != opts.Role:
+			return false
+		case !opts.From.IsZero() && d.timestamp.Before(opts.From):
+			return false
+		case !opts.To.IsZero() && !d.timestamp.Before(opts.To):
+			return false
+		case opts.Allow != nil && !opts.Allow(result(d)):
+			return false
+		}
+		return true
+	}
+
+	var results []SearchResult
+	for _, h := range ss.ix.Search(opts.Query, opts.Limit, keep) {
+		d := ss.docs[h.ID]
+		r := result(d)
+		r.Snippet = search.Snippet(d.content, opts.Query, 160)
+		results = append(results, r)
+	}
+	return results
+}
+
+// FormatSearchResults renders results for the model or a chat.
+func FormatSearchResults(results []SearchResult, loc *time.Location) string {
+	if len(results) == 0 {
+		return "No matching messages."
+	}
+	var sb strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "• [%s] %s #%d %s: %s", r.Timestamp.In(loc).Format("2006-01-02 15:04"),
+			r.SessionKey, r.Index, r.Role, r.Snippet)
+	}
+	return sb.String()
+}
+
+// reKeyChatID extracts the chat ID from keys like "telegram_123" or "cron_123#topic".
+var reKeyChatID = regexp.MustCompile(`^[a-z]+_(-?\d+)(?:#|$)`)
+
+// searchScope limits results to sessions served by the caller's workspace,
+// so tenants never see each other's conversations. Without tenants it keeps
+// to the caller's chat (sessionKey's base and its named sessions) unless
+// searchAllChats is set.
+func (l *Loop) searchScope(ctx context.Context, chatID, sessionKey string) func(SearchResult) bool {
+	if len(l.cfg.Tenants) == 0 {
+		if l.cfg.SearchAllChats {
+			return nil
+		}
+		base, _, _ := strings.Cut(sessionKey, "#")
+		return func(r SearchResult) bool {
+			return r.SessionKey == base || strings.HasPrefix(r.SessionKey, base+"#")
+		}
+	}
+	own := l.tenantFor(ctx, chatID).workspace
+	return func(r SearchResult) bool {
+		cid := r.ChatID
+		if cid == "" {
+			if m := reKeyChatID.FindStringSubmatch(r.SessionKey); m != nil {
+				cid = m[1]
+			}
+		}
+		ws, _ := l.cfg.ResolveTenant(cid, r.UserID)
+		return ws == own
+	}
+}
+
+// parseSearchArgs reads "--from DATE --to DATE --role ROLE query..." as used by /search.
+func parseSearchArgs(args []string, loc *time.Location) (SearchOptions, error) {
+	var opts SearchOptions
+	var words []string
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		if !strings.HasPrefix(flag, "--") || i+1 >= len(args) {
+			words = append(words, flag)
+			continue
+		}
+		val := args[i+1]
+		i++
+		switch flag {
+		case "--from":
+			t, err := time.ParseInLocation("2006-01-02", val, loc)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --from date %q (want YYYY-MM-DD)", val)
+			}
+			opts.From = t
+		case "--to":
+			t, err := time.ParseInLocation("2006-01-02", val, loc)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --to date %q (want YYYY-MM-DD)", val)
+			}
+			opts.To = t.AddDate(0, 0, 1)
+		case "--role":
+			opts.Role = val
+		default:
+			words = append(words, flag, val)
+		}
+	}
+	opts.Query = strings.Join(words, " ")
+	return opts, nil
+}
+
+func (l *Loop) registerSearch() {
+	l.search = NewSessionSearch(l.sessions)
+	loc := l.cfg.Location()
+
+	l.reg.Register(tools.NewSessionSearchTool(loc, func(ctx context.Context, q tools.SessionSearchQuery) (string, error) {
+		c := tools.CallerFrom(ctx)
+		results := l.search.Search(SearchOptions{
+			Query: q.Query, From: q.From, To: q.To, Role: q.Role, Limit: q.Limit,
+			Allow: l.searchScope(ctx, c.ChatID, c.SessionKey),
+		})
+		return FormatSearchResults(results, loc), nil
+	}))
+
+	l.cmds.Register(Command{
+		Name:        "search",
+		Usage:       "[--from YYYY-MM-DD] [--to YYYY-MM-DD] [--role user|assistant] <query>",
+		Description: "Search past conversations",
+		MinArgs:     1,
+		Handler: func(ctx context.Context, req CommandRequest) (string, error) {
+			opts, err := parseSearchArgs(req.Args, loc)
+			if err != nil {
+				return "⚠️ " + err.Error(), nil
+			}
+			if opts.Query == "" {
+				return "Usage: /search <query>", nil
+			}
+			opts.Allow = l.searchScope(ctx, req.ChatID, req.BaseKey)
+			return "🔎 " + FormatSearchResults(l.search.Search(opts), loc), nil
+		},
+	})
+}
@@ -2,14 +2,10 @@
 package agent
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
+	"github.com/yosebyte/miniclaw/internal/tools"
 )
 
 // SessionMessage is a single message stored in session history.
@@ -23,8 +19,11 @@ type SessionMessage struct {
 // Session holds the conversation history for a single chat.
 type Session struct {
 	Key              string           `json:"key"`
+	ChatID           string           `json:"chatId,omitempty"` // chat and user of the latest turn
+	UserID           string           `json:"userId,omitempty"`
 	Messages         []SessionMessage `json:"messages"`
 	LastConsolidated int              `json:"lastConsolidated"`
+	Todos            []tools.TodoItem `json:"todos,omitempty"`
 }
 
 // Add appends a message to the session.
@@ -56,46 +55,36 @@ func (s *Session) RecentMessages(n int) []provider.Message {
 	return result
 }
 
-// Clear resets the session messages.
-func (s *Session) Clear() {
-	s.Messages = nil
-	s.LastConsolidated = 0
-}
-
-// SessionManager manages per-chat sessions.
-type SessionManager struct {
-	dir string
-}
-
-// NewSessionManager creates a manager rooted at dir.
-func NewSessionManager(dir string) *SessionManager {
-	return &SessionManager{dir: dir}
-}
-
-func (m *SessionManager) path(key string) string {
-	safe := strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(key)
-	return filepath.Join(m.dir, safe+".json")
+// RemoveLastTurn drops the most recent user message and everything after it
+// (the assistant reply), returning the removed user message. The tool names
+// recorded on the reply go with it; sessions keep no other tool transcript.
+// LastConsolidated is clamped so it never points past the end.
+func (s *Session) RemoveLastTurn() (SessionMessage, bool) {
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role != "user" {
+			continue
+		}
+		removed := s.Messages[i]
+		s.Messages = s.Messages[:i]
+		if s.LastConsolidated > len(s.Messages) {
+			s.LastConsolidated = len(s.Messages)
+		}
+		return removed, true
+	}
+	return SessionMessage{}, false
 }
 
-// Get loads a session by key, returning an empty one if not found.
-func (m *SessionManager) Get(key string) *Session {
-	s := &Session{Key: key}
-	data, err := os.ReadFile(m.path(key))
-	if err != nil {
-		return s
-	}
-	_ = json.Unmarshal(data, s)
-	return s
+// Clone returns a deep copy of the session.
+func (s *Session) Clone() *Session {
+	c := *s
+	c.Messages = append([]SessionMessage(nil), s.Messages...)
+	c.Todos = append([]tools.TodoItem(nil), s.Todos...)
+	return &c
 }
 
-// Save persists a session to disk.
-func (m *SessionManager) Save(s *Session) error {
-	if err := os.MkdirAll(m.dir, 0700); err != nil {
-		return fmt.Errorf("creating sessions dir: %w", err)
-	}
-	data, err := json.MarshalIndent(s, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(m.path(s.Key), data, 0600)
+// Clear resets the session messages and task list.
+func (s *Session) Clear() {
+	s.Messages = nil
+	s.LastConsolidated = 0
+	s.Todos = nil
 }
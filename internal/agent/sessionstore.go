@@ -0,0 +1,580 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// flushDelay is how long a saved session may stay dirty in memory before it
+// is written out. Close flushes everything immediately.
+const flushDelay = 500 * time.Millisecond
+
+// sessionCacheIdle is how long an unused, fully written session stays cached.
+const sessionCacheIdle = 30 * time.Minute
+
+// Sessions are stored as append-only JSONL. Each line is one record:
+//
+//	{"op":"meta", ...}              session fields other than messages
+//	{"op":"msg","msg":{...}}        one appended message
+//	{"op":"truncate","n":K}         keep only the first K messages (undo, clear)
+//
+// Saving a long session therefore costs one line per new message. When the
+// log holds many more records than live messages it is compacted by an
+// atomic rewrite.
+type sessionRecord struct {
+	Op               string           `json:"op"`
+	Key              string           `json:"key,omitempty"`
+	ChatID           string           `json:"chatId,omitempty"`
+	UserID           string           `json:"userId,omitempty"`
+	LastConsolidated int              `json:"lastConsolidated,omitempty"`
+	Todos            []tools.TodoItem `json:"todos,omitempty"`
+	Msg              *SessionMessage  `json:"msg,omitempty"`
+	N                int              `json:"n,omitempty"`
+}
+
+// sessionEntry is the cached state of one session.
+type sessionEntry struct {
+	mu      sync.Mutex
+	turn    sync.Mutex // serialises whole turns on this session
+	sess    *Session
+	dirty   bool
+	timer   *time.Timer
+	disk    []SessionMessage // messages as persisted
+	meta    string           // last meta record written
+	records int              // records in the file
+	corrupt bool             // file has a damaged record; rewrite before appending
+	used    time.Time        // last access, for eviction
+	turns   int              // holders and waiters of turn; never evicted while > 0
+	evicted bool             // dropped from the cache; look the key up again
+}
+
+// SessionManager caches sessions in memory and persists them with
+// write-behind to crash-safe append-only files, one per key.
+type SessionManager struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]*sessionEntry
+	onSave  []func(*Session)
+}
+
+// NewSessionManager creates a manager rooted at dir.
+func NewSessionManager(dir string) *SessionManager {
+	return &SessionManager{dir: dir, entries: make(map[string]*sessionEntry)}
+}
+
+func (m *SessionManager) path(key string) string {
+	return filepath.Join(m.dir, safeKey(key)+".jsonl")
+}
+
+// legacyPath is the pre-JSONL whole-file format, migrated on first load.
+func (m *SessionManager) legacyPath(key string) string {
+	return filepath.Join(m.dir, safeKey(key)+".json")
+}
+
+// safeKey turns a session key into a file name.
+func safeKey(key string) string {
+	return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(key)
+}
+
+// OnSave registers fn to be called with a snapshot of each session after it
+// is written to disk.
+func (m *SessionManager) OnSave(fn func(*Session)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSave = append(m.onSave, fn)
+}
+
+// List returns every stored session, most recently active first.
+func (m *SessionManager) List() ([]SessionInfo, error) {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var infos []SessionInfo
+	for _, de := range entries {
+		name := de.Name()
+		if de.IsDir() || !(strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".json")) {
+			continue
+		}
+		key := storedKey(filepath.Join(m.dir, name))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		s := m.Peek(key)
+		info := SessionInfo{Key: key, Messages: len(s.Messages)}
+		if _, err := os.Stat(m.path(key)); os.IsNotExist(err) {
+			info.Legacy = true
+		}
+		if n := len(s.Messages); n > 0 {
+			info.LastActivity = s.Messages[n-1].Timestamp
+		} else if fi, err := de.Info(); err == nil {
+			info.LastActivity = fi.ModTime()
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b SessionInfo) int { return b.LastActivity.Compare(a.LastActivity) })
+	return infos, nil
+}
+
+// MigrateLegacy converts every session still stored in the legacy JSON
+// format and returns the keys it converted. Other sessions are converted when
+// first loaded.
+func (m *SessionManager) MigrateLegacy() ([]string, error) {
+	infos, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	var migrated []string
+	for _, info := range infos {
+		if !info.Legacy {
+			continue
+		}
+		e := m.entry(info.Key)
+		e.mu.Unlock()
+		if _, err := os.Stat(m.path(info.Key)); err != nil {
+			return migrated, fmt.Errorf("could not migrate session %s: %w", info.Key, err)
+		}
+		migrated = append(migrated, info.Key)
+	}
+	return migrated, nil
+}
+
+// storedKey reads the session key recorded in a session file.
+func storedKey(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if strings.HasSuffix(path, ".json") {
+		var s struct {
+			Key string `json:"key"`
+		}
+		_ = json.NewDecoder(f).Decode(&s)
+		return s.Key
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		var rec sessionRecord
+		if json.Unmarshal(sc.Bytes(), &rec) == nil && rec.Op == "meta" && rec.Key != "" {
+			return rec.Key
+		}
+	}
+	return ""
+}
+
+// Delete removes a session from the cache and disk, including any legacy
+// files, and drops it from its chat's named-session index.
+func (m *SessionManager) Delete(key string) error {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	delete(m.entries, key)
+	m.mu.Unlock()
+	if ok {
+		e.mu.Lock()
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+		e.evicted = true
+		e.mu.Unlock()
+	}
+
+	for _, p := range []string{m.path(key), m.legacyPath(key), m.legacyPath(key) + ".bak"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if base, name, named := strings.Cut(key, "#"); named {
+		idx := m.Index(base)
+		if _, ok := idx.Sessions[name]; ok {
+			delete(idx.Sessions, name)
+			if idx.Active == name {
+				idx.Active = mainSession
+			}
+			return m.saveIndex(base, idx)
+		}
+	}
+	return nil
+}
+
+// entry returns the cache entry for key with e.mu held, loading it from disk
+// on first use.
+func (m *SessionManager) entry(key string) *sessionEntry {
+	for {
+		m.mu.Lock()
+		e, ok := m.entries[key]
+		if !ok {
+			e = &sessionEntry{}
+			m.entries[key] = e
+		}
+		m.mu.Unlock()
+
+		e.mu.Lock()
+		if e.evicted {
+			e.mu.Unlock()
+			continue
+		}
+		if e.sess == nil {
+			m.load(key, e)
+		}
+		e.used = time.Now()
+		return e
+	}
+}
+
+// Get returns a copy of the session, or an empty one if not found.
+// Changes must be written back with Save or, preferably, made through Update.
+func (m *SessionManager) Get(key string) *Session {
+	e := m.entry(key)
+	defer e.mu.Unlock()
+	return e.sess.Clone()
+}
+
+// Peek returns a copy of the session like Get, but a session that is not
+// cached is read from disk without caching it or migrating a legacy file.
+// It suits scans over every session.
+func (m *SessionManager) Peek(key string) *Session {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.sess != nil && !e.evicted {
+			return e.sess.Clone()
+		}
+	}
+	if _, err := os.Stat(m.path(key)); os.IsNotExist(err) {
+		s := &Session{Key: key}
+		if data, err := os.ReadFile(m.legacyPath(key)); err == nil && json.Unmarshal(data, s) != nil {
+			s = &Session{}
+		}
+		s.Key = key
+		return s
+	}
+	tmp := &sessionEntry{}
+	m.load(key, tmp)
+	return tmp.sess
+}
+
+// Save replaces the stored session with s. Prefer Update when other
+// goroutines may modify the same session concurrently.
+func (m *SessionManager) Save(s *Session) error {
+	e := m.entry(s.Key)
+	defer e.mu.Unlock()
+	e.sess = s.Clone()
+	m.markDirty(s.Key, e)
+	return nil
+}
+
+// Update applies fn to the stored session under its lock and schedules a write.
+func (m *SessionManager) Update(key string, fn func(s *Session)) {
+	e := m.entry(key)
+	defer e.mu.Unlock()
+	fn(e.sess)
+	m.markDirty(key, e)
+}
+
+// Lock serialises turns on one session. It returns the unlock function.
+func (m *SessionManager) Lock(key string) func() {
+	e := m.entry(key)
+	e.turns++
+	e.mu.Unlock()
+	e.turn.Lock()
+	return func() {
+		e.turn.Unlock()
+		e.mu.Lock()
+		e.turns--
+		e.used = time.Now()
+		e.mu.Unlock()
+	}
+}
+
+// Evict drops sessions from the cache that were not used for longer than
+// idle and have nothing left to write, and returns how many it dropped.
+func (m *SessionManager) Evict(idle time.Duration) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for key, e := range m.entries {
+		e.mu.Lock()
+		if !e.dirty && e.timer == nil && e.turns == 0 && time.Since(e.used) > idle {
+			e.evicted = true
+			delete(m.entries, key)
+			n++
+		}
+		e.mu.Unlock()
+	}
+	return n
+}
+
+func (m *SessionManager) markDirty(key string, e *sessionEntry) {
+	e.dirty = true
+	if e.timer == nil {
+		e.timer = time.AfterFunc(flushDelay, func() {
+			if err := m.flushKey(key); err != nil {
+				slog.Error("session flush failed", "key", key, "err", err)
+			}
+		})
+	}
+}
+
+// Flush writes every dirty session to disk.
+func (m *SessionManager) Flush() error {
+	m.mu.Lock()
+	keys := make([]string, 0, len(m.entries))
+	for k := range m.entries {
+		keys = append(keys, k)
+	}
+	m.mu.Unlock()
+
+	var firstErr error
+	for _, k := range keys {
+		if err := m.flushKey(k); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *SessionManager) flushKey(key string) error {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	hooks := m.onSave
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	e.mu.Lock()
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if !e.dirty {
+		e.mu.Unlock()
+		return nil
+	}
+	err := m.write(key, e)
+	if err == nil {
+		e.dirty = false
+	}
+	snap := e.sess.Clone()
+	e.mu.Unlock()
+
+	if err == nil {
+		for _, fn := range hooks {
+			fn(snap)
+		}
+	}
+	return err
+}
+
+// write persists e.sess, appending only what changed since the last write.
+// The caller holds e.mu.
+func (m *SessionManager) write(key string, e *sessionEntry) error {
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return fmt.Errorf("creating sessions dir: %w", err)
+	}
+	cur := e.sess.Messages
+
+	// Compact when the log is mostly history, or when the persisted prefix
+	// no longer matches (messages edited rather than appended or removed).
+	keep := min(len(cur), len(e.disk))
+	prefixOK := keep == 0 || sameMessage(cur[keep-1], e.disk[keep-1])
+	if !prefixOK || e.corrupt || e.records > 2*len(cur)+64 {
+		return m.rewrite(key, e)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	n := 0
+	if len(cur) < len(e.disk) {
+		_ = enc.Encode(sessionRecord{Op: "truncate", N: len(cur)})
+		n++
+	}
+	if meta := metaRecord(e.sess); meta != e.meta {
+		buf.WriteString(meta)
+		n++
+	}
+	for i := keep; i < len(cur); i++ {
+		_ = enc.Encode(sessionRecord{Op: "msg", Msg: &cur[i]})
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(m.path(key), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	e.disk = slices.Clone(cur)
+	e.meta = metaRecord(e.sess)
+	e.records += n
+	return nil
+}
+
+// rewrite replaces the session file with a compacted copy via write-and-rename.
+func (m *SessionManager) rewrite(key string, e *sessionEntry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	meta := metaRecord(e.sess)
+	buf.WriteString(meta)
+	for i := range e.sess.Messages {
+		_ = enc.Encode(sessionRecord{Op: "msg", Msg: &e.sess.Messages[i]})
+	}
+	if err := writeFileAtomic(m.path(key), buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	e.disk = slices.Clone(e.sess.Messages)
+	e.meta = meta
+	e.records = 1 + len(e.sess.Messages)
+	e.corrupt = false
+	return nil
+}
+
+// load reads a session into e, migrating the legacy JSON format if needed.
+// The caller holds e.mu.
+func (m *SessionManager) load(key string, e *sessionEntry) {
+	e.sess = &Session{Key: key}
+	f, err := os.Open(m.path(key))
+	if os.IsNotExist(err) {
+		m.migrate(key, e)
+		return
+	}
+	if err != nil {
+		slog.Warn("could not open session", "key", key, "err", err)
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		var rec sessionRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			// A torn final line from a crash mid-append; everything before it is intact.
+			slog.Warn("skipping corrupt session record", "key", key, "err", err)
+			e.corrupt = true
+			continue
+		}
+		e.records++
+		switch rec.Op {
+		case "meta":
+			e.sess.ChatID = rec.ChatID
+			e.sess.UserID = rec.UserID
+			e.sess.LastConsolidated = rec.LastConsolidated
+			e.sess.Todos = rec.Todos
+		case "msg":
+			if rec.Msg != nil {
+				e.sess.Messages = append(e.sess.Messages, *rec.Msg)
+			}
+		case "truncate":
+			if rec.N < len(e.sess.Messages) {
+				e.sess.Messages = e.sess.Messages[:rec.N]
+			}
+		}
+	}
+	e.disk = slices.Clone(e.sess.Messages)
+	e.meta = metaRecord(e.sess)
+}
+
+// migrate converts a legacy <key>.json file into the JSONL format, keeping
+// the original as <key>.json.bak.
+func (m *SessionManager) migrate(key string, e *sessionEntry) {
+	legacy := m.legacyPath(key)
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, e.sess); err != nil {
+		slog.Warn("could not migrate legacy session", "key", key, "err", err)
+		e.sess = &Session{Key: key}
+		return
+	}
+	e.sess.Key = key
+	if err := m.rewrite(key, e); err != nil {
+		slog.Warn("could not migrate legacy session", "key", key, "err", err)
+		return
+	}
+	if err := os.Rename(legacy, legacy+".bak"); err != nil {
+		slog.Warn("could not move legacy session aside", "path", legacy, "err", err)
+	}
+	slog.Info("session migrated to JSONL", "key", key, "messages", len(e.sess.Messages))
+}
+
+func metaRecord(s *Session) string {
+	data, _ := json.Marshal(sessionRecord{Op: "meta", Key: s.Key, ChatID: s.ChatID, UserID: s.UserID,
+		LastConsolidated: s.LastConsolidated, Todos: s.Todos})
+	return string(data) + "\n"
+}
+
+func sameMessage(a, b SessionMessage) bool {
+	return a.Role == b.Role && a.Timestamp.Equal(b.Timestamp) && a.Content == b.Content
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so readers and crashes see either the old or the new content.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSkillFileBytes caps how much of each bundled file load_skill returns inline.
+const maxSkillFileBytes = 32 * 1024
+
+// Skill is an on-demand instruction set stored in workspace/skills/<name>/SKILL.md.
+// Only its name and description go into the system prompt; the body is loaded
+// through the load_skill tool when the model decides it is relevant.
+type Skill struct {
+	Name        string
+	Description string
+	Dir         string
+}
+
+// ListSkills returns the skills found under workspace/skills, sorted by name.
+func ListSkills(workspace string) []Skill {
+	root := filepath.Join(workspace, "skills")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var skills []Skill
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+		if err != nil {
+			continue
+		}
+		fm, body := parseFrontmatter(string(data))
+		s := Skill{
+			Name:        fm.get("name"),
+			Description: fm.get("description"),
+			Dir:         dir,
+		}
+		if s.Name == "" {
+			s.Name = e.Name()
+		}
+		if s.Description == "" {
+			first, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+			s.Description = truncateRunes(strings.TrimLeft(first, "# "), 120)
+		}
+		skills = append(skills, s)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+	return skills
+}
+
+// LoadSkill returns the full SKILL.md instructions for the named skill,
+// followed by the bundled files in its directory.
+func LoadSkill(workspace, name string) (string, error) {
+	var skill *Skill
+	for _, s := range ListSkills(workspace) {
+		if strings.EqualFold(s.Name, name) || strings.EqualFold(filepath.Base(s.Dir), name) {
+			skill = &s
+			break
+		}
+	}
+	if skill == nil {
+		return "", fmt.Errorf("skill %q not found", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(skill.Dir, "SKILL.md"))
+	if err != nil {
+		return "", err
+	}
+	_, body := parseFrontmatter(string(data))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Skill: %s\nDirectory: %s\n\n%s\n", skill.Name, skill.Dir, strings.TrimSpace(body))
+
+	var files []string
+	_ = filepath.WalkDir(skill.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != skill.Dir {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && path != filepath.Join(skill.Dir, "SKILL.md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		return sb.String(), nil
+	}
+
+	sb.WriteString("\n## Bundled files\n")
+	for _, path := range files {
+		rel, _ := filepath.Rel(skill.Dir, path)
+		data, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&sb, "\n### %s\n(unreadable: %v)\n", rel, err)
+		case len(data) > maxSkillFileBytes || !utf8.Valid(data):
+			fmt.Fprintf(&sb, "\n### %s\n(%d bytes, not shown; path: %s)\n", rel, len(data), path)
+		default:
+			fmt.Fprintf(&sb, "\n### %s\nPath: %s\n```\n%s\n```\n", rel, path, strings.TrimRight(string(data), "\n"))
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,62 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package agent
+
+import (
+	"context"
+
+	"github.com/yosebyte/miniclaw/internal/tools"
+)
+
+// TodoFunc is notified when a chat's task list changes (Telegram pins it as progress).
+type TodoFunc func(chatID string, items []tools.TodoItem)
+
+// SetTodoFunc sets the callback for task-list changes.
+func (l *Loop) SetTodoFunc(fn TodoFunc) {
+	l.todoFn = fn
+}
+
+func (l *Loop) registerTodoTools() {
+	l.reg.Register(tools.NewTodoWriteTool(func(ctx context.Context, items []tools.TodoItem) {
+		c := tools.CallerFrom(ctx)
+		l.setTodos(c.SessionKey, c.ChatID, items)
+	}))
+	l.reg.Register(tools.NewTodoReadTool(func(ctx context.Context) []tools.TodoItem {
+		return l.todoList(tools.CallerFrom(ctx).SessionKey)
+	}))
+}
+
+// todoList returns a copy of the session's task list.
+func (l *Loop) todoList(sessionKey string) []tools.TodoItem {
+	l.todoMu.Lock()
+	defer l.todoMu.Unlock()
+	return append([]tools.TodoItem(nil), l.todos[sessionKey]...)
+}
+
+// setTodos replaces the session's task list and notifies the channel.
+func (l *Loop) setTodos(sessionKey, chatID string, items []tools.TodoItem) {
+	l.todoMu.Lock()
+	l.todos[sessionKey] = append([]tools.TodoItem(nil), items...)
+	l.todoMu.Unlock()
+	if l.todoFn != nil && chatID != "" {
+		l.todoFn(chatID, items)
+	}
+}
+
+// loadTodos seeds the in-memory task list from a persisted session the first
+// time the session is seen in this process.
+func (l *Loop) loadTodos(s *Session) {
+	l.todoMu.Lock()
+	defer l.todoMu.Unlock()
+	if _, ok := l.todos[s.Key]; !ok {
+		l.todos[s.Key] = s.Todos
+	}
+}
+
+// todoPrompt renders the task list for inclusion in each model call.
+func (l *Loop) todoPrompt(sessionKey string) string {
+	items := l.todoList(sessionKey)
+	if len(items) == 0 {
+		return ""
+	}
+	return "\n\n## Current Task List\nKeep this list up to date with todo_write as you work.\n" + tools.FormatTodos(items)
+}
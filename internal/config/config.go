@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 )
 
 // Config is the root configuration for miniclaw.
@@ -14,6 +16,24 @@ type Config struct {
 	Telegram  TelegramConfig  `json:"telegram"`
 	Heartbeat HeartbeatConfig `json:"heartbeat"`
 	Workspace string          `json:"workspace"`
+	Timezone  string          `json:"timezone"` // IANA name, e.g. "Europe/Berlin"; empty means local time
+	Tenants   []TenantConfig  `json:"tenants,omitempty"`
+	AuditLog  string          `json:"auditLog,omitempty"` // JSONL file of tool calls; empty disables
+	Tools     ToolsConfig     `json:"tools"`
+
+	IdleTimeoutHours float64 `json:"idleTimeoutHours,omitempty"` // start a new session after this many quiet hours; 0 disables
+	SearchAllChats   bool    `json:"searchAllChats,omitempty"`   // without tenants, let session search cover every chat
+}
+
+// TenantConfig gives a set of chats or users their own workspace and persona.
+// Requests that match no tenant use the default workspace and SOUL.md.
+type TenantConfig struct {
+	ChatIDs   []string `json:"chatIds,omitempty"`
+	UserIDs   []string `json:"userIds,omitempty"`
+	Workspace string   `json:"workspace"`
+	Persona   string   `json:"persona,omitempty"` // persona file inside the workspace; default SOUL.md
+
+	IdleTimeoutHours *float64 `json:"idleTimeoutHours,omitempty"` // overrides the global idleTimeoutHours
 }
 
 // ProviderConfig holds Claude provider settings.
@@ -25,12 +45,56 @@ type ProviderConfig struct {
 	MaxTokens     int    `json:"maxTokens"`
 	MaxIterations int    `json:"maxIterations"`
 	MemoryWindow  int    `json:"memoryWindow"`
+	MemoryMaxSize int    `json:"memoryMaxSize,omitempty"` // MEMORY.md bytes before `memory compact` reorganizes it; default 8192
+}
+
+// ToolsConfig restricts what the agent's tools may do.
+type ToolsConfig struct {
+	FS   FSConfig   `json:"fs"`
+	Exec ExecConfig `json:"exec"`
+}
+
+// FSConfig limits the paths read_file, write_file, edit_file and list_dir may
+// touch. Relative roots are relative to the workspace serving the request.
+type FSConfig struct {
+	AllowedRoots  []string `json:"allowedRoots,omitempty"`  // read-write roots; empty means the workspace only
+	ReadOnlyRoots []string `json:"readOnlyRoots,omitempty"` // roots that may be read but not written
+	DeniedGlobs   []string `json:"deniedGlobs,omitempty"`   // e.g. "*.pem", ".env", "**/.git/**"
+}
+
+// ExecConfig controls the exec tool.
+type ExecConfig struct {
+	Sandbox          SandboxConfig `json:"sandbox"`
+	PersistentShell  bool          `json:"persistentShell,omitempty"`  // keep one shell per chat session so cd and exports persist
+	ShellIdleMinutes int           `json:"shellIdleMinutes,omitempty"` // kill persistent shells unused this long; default 30
+	Rules            []ExecRules   `json:"rules,omitempty"`
+}
+
+// ExecRules allows or denies exec and process_start commands for the chats and
+// users listed, or for everyone when none are. Patterns are globs, a bare
+// program name, or regexps prefixed with "re:".
+type ExecRules struct {
+	ChatIDs []string `json:"chatIds,omitempty"`
+	UserIDs []string `json:"userIds,omitempty"`
+	Allow   []string `json:"allow,omitempty"` // if any apply, every command must match one
+	Deny    []string `json:"deny,omitempty"`
+}
+
+// SandboxConfig runs exec commands in a Linux sandbox: the workspace is
+// writable, the rest of the filesystem read-only. Zero limits use defaults.
+type SandboxConfig struct {
+	Enabled    bool `json:"enabled"`
+	Network    bool `json:"network,omitempty"`    // allow network access; off by default
+	CPUSeconds int  `json:"cpuSeconds,omitempty"` // default 60
+	MemoryMB   int  `json:"memoryMB,omitempty"`   // address space limit; default 2048
+	MaxProcs   int  `json:"maxProcs,omitempty"`   // per-user process limit; default 512
 }
 
 // TelegramConfig holds Telegram bot settings.
 type TelegramConfig struct {
-	Token     string   `json:"token"`
-	AllowFrom []string `json:"allowFrom"`
+	Token        string   `json:"token"`
+	AllowFrom    []string `json:"allowFrom"`
+	ShowProgress bool     `json:"showProgress"` // live "🔧 exec: ..." status line while tools run
 }
 
 // HeartbeatConfig controls the proactive heartbeat.
@@ -96,11 +160,110 @@ func (c *Config) WorkspacePath() string {
 	if c.Workspace == "" {
 		c.Workspace = "~/.miniclaw/workspace"
 	}
-	if len(c.Workspace) >= 2 && c.Workspace[:2] == "~/" {
+	return expandHome(c.Workspace)
+}
+
+// SessionsPath returns the directory holding conversation sessions.
+func (c *Config) SessionsPath() string {
+	return filepath.Join(filepath.Dir(c.WorkspacePath()), "sessions")
+}
+
+// GatewayLockPath returns the lock file a running gateway holds next to the
+// workspace and sessions it serves.
+func (c *Config) GatewayLockPath() string {
+	return filepath.Join(filepath.Dir(c.WorkspacePath()), "gateway.lock")
+}
+
+// tenant returns the tenant serving chatID and userID, or nil for the default.
+// Chat matches take precedence over user matches.
+func (c *Config) tenant(chatID, userID string) *TenantConfig {
+	for i, t := range c.Tenants {
+		if chatID != "" && slices.Contains(t.ChatIDs, chatID) {
+			return &c.Tenants[i]
+		}
+	}
+	for i, t := range c.Tenants {
+		if userID != "" && slices.Contains(t.UserIDs, userID) {
+			return &c.Tenants[i]
+		}
+	}
+	return nil
+}
+
+// ResolveTenant returns the workspace and persona file for a request from
+// chatID and userID. Chat matches take precedence over user matches.
+func (c *Config) ResolveTenant(chatID, userID string) (workspace, persona string) {
+	workspace, persona = c.WorkspacePath(), "SOUL.md"
+	if t := c.tenant(chatID, userID); t != nil {
+		if t.Workspace != "" {
+			workspace = expandHome(t.Workspace)
+		}
+		if t.Persona != "" {
+			persona = t.Persona
+		}
+	}
+	return workspace, persona
+}
+
+// IdleTimeout returns how long the chat may be quiet before its session is
+// reset, or 0 if it never is.
+func (c *Config) IdleTimeout(chatID, userID string) time.Duration {
+	hours := c.IdleTimeoutHours
+	if t := c.tenant(chatID, userID); t != nil && t.IdleTimeoutHours != nil {
+		hours = *t.IdleTimeoutHours
+	}
+	if hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// ShellIdleTimeout returns how long a persistent shell may sit unused.
+func (c *Config) ShellIdleTimeout() time.Duration {
+	if c.Tools.Exec.ShellIdleMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.Tools.Exec.ShellIdleMinutes) * time.Minute
+}
+
+// Workspaces returns the default workspace followed by each distinct tenant workspace.
+func (c *Config) Workspaces() []string {
+	list := []string{c.WorkspacePath()}
+	for _, t := range c.Tenants {
+		if t.Workspace == "" {
+			continue
+		}
+		ws := expandHome(t.Workspace)
+		if !slices.Contains(list, ws) {
+			list = append(list, ws)
+		}
+	}
+	return list
+}
+
+func expandHome(path string) string {
+	if len(path) >= 2 && path[:2] == "~/" {
 		home, _ := os.UserHomeDir()
-		return filepath.Join(home, c.Workspace[2:])
+		return filepath.Join(home, path[2:])
 	}
-	return c.Workspace
+	return path
+}
+
+// Location returns the user's configured timezone, falling back to local time.
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// AuditLogPath returns the expanded audit log path, or "" if disabled.
+func (c *Config) AuditLogPath() string {
+	return expandHome(c.AuditLog)
 }
 
 // CronPath returns the path for persisted cron jobs.
@@ -45,11 +45,18 @@ type ContentBlock struct {
 
 // ChatRequest is the payload for the Messages API.
 type ChatRequest struct {
-	Model     string           `json:"model"`
-	MaxTokens int              `json:"max_tokens"`
-	System    string           `json:"system,omitempty"`
-	Messages  []Message        `json:"messages"`
-	Tools     []ToolDefinition `json:"tools,omitempty"`
+	Model      string           `json:"model"`
+	MaxTokens  int              `json:"max_tokens"`
+	System     string           `json:"system,omitempty"`
+	Messages   []Message        `json:"messages"`
+	Tools      []ToolDefinition `json:"tools,omitempty"`
+	ToolChoice *ToolChoice      `json:"tool_choice,omitempty"`
+}
+
+// ToolChoice controls whether and which tool the model must call.
+type ToolChoice struct {
+	Type string `json:"type"`           // "auto", "any" or "tool"
+	Name string `json:"name,omitempty"` // with Type "tool"
 }
 
 // ChatResponse is the response from the Messages API.
@@ -83,9 +90,23 @@ func New(cfg *config.Config) *Claude {
 	}
 }
 
+// ChatOptions overrides configured settings for a single request.
+type ChatOptions struct {
+	Model      string      // empty means the configured model
+	ToolChoice *ToolChoice // nil lets the model decide
+}
+
 // Chat sends messages to the Claude API and returns the response.
 func (c *Claude) Chat(ctx context.Context, system string, messages []Message, tools []ToolDefinition) (*ChatResponse, error) {
-	model := c.cfg.Provider.Model
+	return c.ChatWith(ctx, ChatOptions{}, system, messages, tools)
+}
+
+// ChatWith is Chat with per-request overrides.
+func (c *Claude) ChatWith(ctx context.Context, opts ChatOptions, system string, messages []Message, tools []ToolDefinition) (*ChatResponse, error) {
+	model := opts.Model
+	if model == "" {
+		model = c.cfg.Provider.Model
+	}
 	if model == "" {
 		model = "claude-opus-4-5"
 	}
@@ -95,11 +116,12 @@ func (c *Claude) Chat(ctx context.Context, system string, messages []Message, to
 	}
 
 	req := ChatRequest{
-		Model:     model,
-		MaxTokens: maxTokens,
-		System:    system,
-		Messages:  messages,
-		Tools:     tools,
+		Model:      model,
+		MaxTokens:  maxTokens,
+		System:     system,
+		Messages:   messages,
+		Tools:      tools,
+		ToolChoice: opts.ToolChoice,
 	}
 
 	resp, err := c.doRequest(ctx, req)
@@ -0,0 +1,212 @@
+// MIT License - Copyright (c) 2026 yosebyte
+
+// Package search provides a small in-memory inverted index with BM25 ranking.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Hit is one ranked search result.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+type doc struct {
+	length int
+	terms  map[string]int
+}
+
+// Index is an inverted index over documents identified by string IDs.
+// It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*doc
+	postings map[string]map[string]int // term -> doc ID -> term frequency
+	totalLen int
+}
+
+// New creates an empty Index.
+func New() *Index {
+	return &Index{docs: make(map[string]*doc), postings: make(map[string]map[string]int)}
+}
+
+// Add indexes text under id, replacing any previous document with that id.
+func (ix *Index) Add(id, text string) {
+	tokens := Tokenize(text)
+	terms := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		terms[t]++
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+	ix.docs[id] = &doc{length: len(tokens), terms: terms}
+	ix.totalLen += len(tokens)
+	for t, n := range terms {
+		p := ix.postings[t]
+		if p == nil {
+			p = make(map[string]int)
+			ix.postings[t] = p
+		}
+		p[id] = n
+	}
+}
+
+// Remove deletes a document.
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id string) {
+	d, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for t := range d.terms {
+		delete(ix.postings[t], id)
+		if len(ix.postings[t]) == 0 {
+			delete(ix.postings, t)
+		}
+	}
+	ix.totalLen -= d.length
+	delete(ix.docs, id)
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Search ranks documents matching any query term by BM25 and returns up to
+// limit hits, best first. keep, if non-nil, filters candidate IDs.
+func (ix *Index) Search(query string, limit int, keep func(id string) bool) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if len(ix.docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(ix.docs))
+	avgLen := float64(ix.totalLen) / n
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		p := ix.postings[t]
+		if len(p) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-float64(len(p))+0.5)/(float64(len(p))+0.5))
+		for id, tf := range p {
+			if keep != nil && !keep(id) {
+				continue
+			}
+			dl := float64(ix.docs[id].length)
+			f := float64(tf)
+			scores[id] += idf * f * (k1 + 1) / (f + k1*(1-b+b*dl/avgLen))
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, s := range scores {
+		hits = append(hits, Hit{ID: id, Score: s})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Tokenize lowercases text and splits it into letter/digit runs. Runs of
+// Han, Hiragana, Katakana or Hangul characters are split into single
+// characters so unsegmented scripts remain searchable.
+func Tokenize(text string) []string {
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isIdeographic(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			cur.WriteRune(r)
+		case (r == '_' || r == '-' || r == '.') && cur.Len() > 0:
+			// keep identifiers like go_test, git-rebase and file.txt together
+			cur.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	for i, t := range tokens {
+		tokens[i] = strings.TrimRight(t, "_-.")
+	}
+	return tokens
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Snippet returns about width runes of text around the first occurrence of
+// a query term, with ellipses where text was cut.
+func Snippet(text, query string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	lower := strings.ToLower(text)
+	pos := -1
+	for _, t := range Tokenize(query) {
+		if i := strings.Index(lower, t); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	start := 0
+	if pos > 0 {
+		start = len([]rune(lower[:pos])) - width/3
+		start = max(0, min(start, len(runes)-width))
+	}
+	end := min(len(runes), start+width)
+	out := string(runes[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
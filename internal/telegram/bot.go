@@ -13,6 +13,7 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/yosebyte/miniclaw/internal/agent"
 	"github.com/yosebyte/miniclaw/internal/config"
+	"github.com/yosebyte/miniclaw/internal/tools"
 )
 
 // Bot is the Telegram long-polling bot.
@@ -21,16 +22,40 @@ type Bot struct {
 	loop   *agent.Loop
 	api    *tgbotapi.BotAPI
 	typing sync.Map // chat_id(int64) -> context.CancelFunc
+
+	progressMu sync.Mutex
+	progress   map[int64]int // chat_id -> message ID of the pinned task list
+
+	statusMu sync.Mutex
+	status   map[int64]*statusLine // chat_id -> live tool status of the running turn
 }
 
 // New creates a Bot. Call SetLoop before Run.
 func New(cfg *config.Config, loop *agent.Loop) *Bot {
-	return &Bot{cfg: cfg, loop: loop}
+	b := &Bot{cfg: cfg, progress: make(map[int64]int), status: make(map[int64]*statusLine)}
+	b.SetLoop(loop)
+	return b
 }
 
 // SetLoop sets the agent loop (used when loop is created after bot).
 func (b *Bot) SetLoop(loop *agent.Loop) {
 	b.loop = loop
+	if loop == nil {
+		return
+	}
+	loop.Commands().Register(agent.Command{
+		Name:        "start",
+		Description: "Start the bot",
+		Handler: func(ctx context.Context, _ agent.CommandRequest) (string, error) {
+			return fmt.Sprintf("👋 Hi %s! I'm miniclaw.\n\nSend me a message and I'll respond!\nType /help to see available commands.",
+				agent.OriginFrom(ctx).UserName), nil
+		},
+	})
+	loop.Commands().OnChange(b.syncCommands)
+	loop.SetTodoFunc(b.showTodos)
+	if b.cfg.Telegram.ShowProgress {
+		loop.Subscribe(agent.ObserverFunc(b.showStatus))
+	}
 }
 
 // Run starts long polling and blocks until ctx is cancelled.
@@ -47,14 +72,7 @@ func (b *Bot) Run(ctx context.Context) error {
 	b.api = api
 	slog.Info("telegram bot connected", "username", api.Self.UserName)
 
-	cmds := tgbotapi.NewSetMyCommands(
-		tgbotapi.BotCommand{Command: "start", Description: "Start the bot"},
-		tgbotapi.BotCommand{Command: "new", Description: "Start a new conversation"},
-		tgbotapi.BotCommand{Command: "help", Description: "Show available commands"},
-	)
-	if _, err := api.Request(cmds); err != nil {
-		slog.Warn("could not set bot commands", "err", err)
-	}
+	b.syncCommands()
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 30
@@ -105,15 +123,12 @@ func (b *Bot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	}
 	slog.Info("message received", "from", user.ID, "chat", chatID, "preview", preview)
 
-	if strings.EqualFold(strings.TrimSpace(text), "/start") {
-		reply := tgbotapi.NewMessage(chatID,
-			fmt.Sprintf("👋 Hi %s! I'm miniclaw.\n\nSend me a message and I'll respond!\nType /help to see available commands.", user.FirstName))
-		if _, err := b.api.Send(reply); err != nil {
-			slog.Warn("send error", "err", err)
-		}
-		return
-	}
-
+	ctx = agent.WithOrigin(ctx, agent.Origin{
+		Source:    "telegram",
+		UserID:    fmt.Sprintf("%d", user.ID),
+		UserName:  user.FirstName,
+		ChatTitle: msg.Chat.Title,
+	})
 	sessionKey := fmt.Sprintf("telegram_%d", chatID)
 
 	typingCtx, typingCancel := context.WithCancel(ctx)
@@ -135,6 +150,33 @@ func (b *Bot) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	b.sendText(chatID, response)
 }
 
+// syncCommands publishes the loop's command registry as the Telegram menu.
+func (b *Bot) syncCommands() {
+	if b.api == nil || b.loop == nil {
+		return
+	}
+	var menu []tgbotapi.BotCommand
+	for _, c := range b.loop.Commands().List() {
+		if !reTelegramCommand.MatchString(c.Name) {
+			continue
+		}
+		desc := c.Description
+		if desc == "" {
+			desc = c.Name
+		}
+		if len(desc) > 256 {
+			desc = desc[:253] + "..."
+		}
+		menu = append(menu, tgbotapi.BotCommand{Command: c.Name, Description: desc})
+	}
+	if _, err := b.api.Request(tgbotapi.NewSetMyCommands(menu...)); err != nil {
+		slog.Warn("could not set bot commands", "err", err)
+	}
+}
+
+// reTelegramCommand matches the command names Telegram accepts in its menu.
+var reTelegramCommand = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
 func (b *Bot) typingLoop(ctx context.Context, chatID int64) {
 	b.sendTyping(chatID)
 	ticker := time.NewTicker(4 * time.Second)
@@ -178,6 +220,158 @@ func (b *Bot) sendText(chatID int64, content string) {
 	}
 }
 
+// showTodos keeps a single pinned message per chat in sync with the agent's
+// task list. It is edited in place as items complete and unpinned when the
+// list is finished or cleared.
+func (b *Bot) showTodos(chatIDStr string, items []tools.TodoItem) {
+	if b.api == nil {
+		return
+	}
+	var chatID int64
+	if _, err := fmt.Sscanf(chatIDStr, "%d", &chatID); err != nil {
+		return
+	}
+
+	b.progressMu.Lock()
+	defer b.progressMu.Unlock()
+	msgID, pinned := b.progress[chatID]
+
+	finished := len(items) > 0
+	for _, it := range items {
+		if it.Status != "completed" {
+			finished = false
+		}
+	}
+
+	if len(items) == 0 {
+		if pinned {
+			b.unpin(chatID, msgID)
+			delete(b.progress, chatID)
+		}
+		return
+	}
+
+	text := "📋 " + tools.FormatTodos(items)
+	if pinned {
+		edit := tgbotapi.NewEditMessageText(chatID, msgID, text)
+		if _, err := b.api.Send(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+			slog.Debug("task list edit failed", "err", err)
+		}
+	} else {
+		sent, err := b.api.Send(tgbotapi.NewMessage(chatID, text))
+		if err != nil {
+			slog.Warn("task list send failed", "err", err)
+			return
+		}
+		msgID = sent.MessageID
+		b.progress[chatID] = msgID
+		pin := tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: msgID, DisableNotification: true}
+		if _, err := b.api.Request(pin); err != nil {
+			slog.Debug("task list pin failed", "err", err)
+		}
+	}
+
+	if finished {
+		b.unpin(chatID, msgID)
+		delete(b.progress, chatID)
+	}
+}
+
+// statusInterval is the least time between edits of a chat's status line;
+// Telegram throttles bots that edit one chat faster.
+const statusInterval = time.Second
+
+// statusLine is the live tool status of one chat during a turn.
+type statusLine struct {
+	wake chan struct{} // buffered; signals that text or done changed
+	text string
+	done bool // the turn finished; remove the message
+}
+
+// showStatus records the tool that is running, or that the turn finished, and
+// returns at once: a goroutine per chat sends the latest state to Telegram,
+// so the agent loop never waits on the API.
+func (b *Bot) showStatus(e agent.Event) {
+	if b.api == nil {
+		return
+	}
+	var chatID int64
+	if _, err := fmt.Sscanf(e.ChatID, "%d", &chatID); err != nil {
+		return
+	}
+
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	st := b.status[chatID]
+	switch e.Kind {
+	case agent.EventToolStarted, agent.EventToolFinished:
+		if st == nil {
+			st = &statusLine{wake: make(chan struct{}, 1)}
+			b.status[chatID] = st
+			go b.runStatus(chatID, st)
+		}
+		st.text = agent.FormatStatus(e)
+	case agent.EventTurnFinished:
+		if st == nil {
+			return
+		}
+		st.done = true
+		delete(b.status, chatID)
+	default:
+		return
+	}
+	select {
+	case st.wake <- struct{}{}:
+	default: // a wake-up is already pending and will see the new state
+	}
+}
+
+// runStatus sends, edits and finally deletes the status message of one turn,
+// coalescing changes that arrive faster than statusInterval.
+func (b *Bot) runStatus(chatID int64, st *statusLine) {
+	var msgID int
+	var shown string
+	for range st.wake {
+		b.statusMu.Lock()
+		text, done := st.text, st.done
+		b.statusMu.Unlock()
+
+		switch {
+		case done:
+			if msgID != 0 {
+				if _, err := b.api.Request(tgbotapi.NewDeleteMessage(chatID, msgID)); err != nil {
+					slog.Debug("status delete failed", "err", err)
+				}
+			}
+			return
+		case text == shown:
+			continue
+		case msgID != 0:
+			if _, err := b.api.Send(tgbotapi.NewEditMessageText(chatID, msgID, text)); err != nil {
+				slog.Debug("status edit failed", "err", err)
+			}
+		default:
+			m := tgbotapi.NewMessage(chatID, text)
+			m.DisableNotification = true
+			sent, err := b.api.Send(m)
+			if err != nil {
+				slog.Debug("status send failed", "err", err)
+				break
+			}
+			msgID = sent.MessageID
+		}
+		shown = text
+		time.Sleep(statusInterval)
+	}
+}
+
+func (b *Bot) unpin(chatID int64, msgID int) {
+	unpin := tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: msgID}
+	if _, err := b.api.Request(unpin); err != nil {
+		slog.Debug("task list unpin failed", "err", err)
+	}
+}
+
 // Send delivers a message to a chat by ID string (used by cron and heartbeat).
 func (b *Bot) Send(chatID, text string) error {
 	if b.api == nil {
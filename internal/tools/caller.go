@@ -0,0 +1,37 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// Caller identifies the request a tool call is made for.
+type Caller struct {
+	SessionKey string
+	ChatID     string
+	UserID     string
+	Workspace  string // workspace root for this chat; relative paths resolve against it
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying c.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// CallerFrom returns the Caller stored in ctx, if any.
+func CallerFrom(ctx context.Context) Caller {
+	c, _ := ctx.Value(callerKey{}).(Caller)
+	return c
+}
+
+// resolvePath expands ~ and makes relative paths relative to the caller's workspace.
+func resolvePath(ctx context.Context, path string) string {
+	path = expandHome(path)
+	if ws := CallerFrom(ctx).Workspace; ws != "" && !filepath.IsAbs(path) {
+		return filepath.Join(ws, path)
+	}
+	return path
+}
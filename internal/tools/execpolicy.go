@@ -0,0 +1,184 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CommandRuleSet allows or denies shell commands for the chats and users it
+// names; a set naming neither applies to every chat.
+//
+// A pattern is either a glob or, with a "re:" prefix, a regular expression.
+// A bare word such as "git" matches any command run by that program; an
+// allowed one must be run by name or from where $PATH finds it. Other
+// globs match the whole normalised command ("rm -rf /*"), where * matches
+// anything; regexps only need to match part of it.
+type CommandRuleSet struct {
+	ChatIDs []string
+	UserIDs []string
+	Allow   []string // when any applicable set has some, every command must match one
+	Deny    []string // checked against each command, each pipeline and the whole line
+}
+
+// CommandPolicy decides whether exec and process_start may run a command.
+// A nil *CommandPolicy allows everything.
+type CommandPolicy struct {
+	sets []commandRules
+	err  error // invalid rules; every command is denied
+}
+
+type commandRules struct {
+	chatIDs, userIDs []string
+	allow, deny      []commandPattern
+}
+
+type commandPattern struct {
+	text    string
+	program string // for bare words
+	re      *regexp.Regexp
+}
+
+// NewCommandPolicy compiles the rule sets. If a rule is invalid it returns the
+// error along with a policy that denies every command, so a typo in the
+// config never leaves exec unrestricted.
+func NewCommandPolicy(sets []CommandRuleSet) (*CommandPolicy, error) {
+	p := &CommandPolicy{}
+	for _, s := range sets {
+		r := commandRules{chatIDs: s.ChatIDs, userIDs: s.UserIDs}
+		for _, list := range []struct {
+			in  []string
+			out *[]commandPattern
+		}{{s.Allow, &r.allow}, {s.Deny, &r.deny}} {
+			for _, text := range list.in {
+				pat, err := compileCommandPattern(text)
+				if err != nil {
+					return &CommandPolicy{err: err}, err
+				}
+				*list.out = append(*list.out, pat)
+			}
+		}
+		p.sets = append(p.sets, r)
+	}
+	return p, nil
+}
+
+func compileCommandPattern(text string) (commandPattern, error) {
+	if expr, ok := strings.CutPrefix(text, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return commandPattern{}, fmt.Errorf("exec rule %q: %w", text, err)
+		}
+		return commandPattern{text: text, re: re}, nil
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if !strings.ContainsAny(text, " *?") {
+		return commandPattern{text: text, program: text}, nil
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range text {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return commandPattern{text: text, re: regexp.MustCompile(sb.String())}, nil
+}
+
+// match reports whether command matches. A bare word denies the program
+// under any path, but allows it only by its plain name or the absolute path
+// $PATH resolves it to, so a script named like it elsewhere is not allowed.
+func (c commandPattern) match(command string, allow bool) bool {
+	if c.program == "" {
+		return c.re.MatchString(command)
+	}
+	name, _, _ := strings.Cut(command, " ")
+	if name == c.program {
+		return true
+	}
+	if !allow {
+		return filepath.Base(name) == c.program
+	}
+	if !filepath.IsAbs(name) || filepath.Base(name) != c.program {
+		return false
+	}
+	path, err := exec.LookPath(c.program)
+	return err == nil && filepath.Clean(name) == path
+}
+
+// Check returns an error explaining why command may not run for the caller,
+// or nil. Denials and allow matches are logged.
+func (p *CommandPolicy) Check(ctx context.Context, command string) error {
+	if p == nil {
+		return nil
+	}
+	caller := CallerFrom(ctx)
+	if p.err != nil {
+		return fmt.Errorf("command denied: the exec policy is invalid (%v)", p.err)
+	}
+	var allow, deny []commandPattern
+	for _, s := range p.sets {
+		global := len(s.chatIDs) == 0 && len(s.userIDs) == 0
+		if global || slices.Contains(s.chatIDs, caller.ChatID) || slices.Contains(s.userIDs, caller.UserID) {
+			allow = append(allow, s.allow...)
+			deny = append(deny, s.deny...)
+		}
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+	denied := func(format string, args ...any) error {
+		reason := fmt.Sprintf(format, args...)
+		slog.Warn("exec command denied", "session", caller.SessionKey, "command", command, "reason", reason)
+		return fmt.Errorf("command denied: %s", reason)
+	}
+
+	parsed, err := splitShell(command)
+	if err != nil {
+		return denied("cannot check it (%v); simplify the command", err)
+	}
+	whole := strings.Join(strings.Fields(command), " ")
+	candidates := append(append([]string{whole}, parsed.pipelines...), parsed.commands...)
+	for _, text := range candidates {
+		for _, rule := range deny {
+			if rule.match(text, false) {
+				return denied("%q matches the deny rule %q", text, rule.text)
+			}
+		}
+	}
+	if len(allow) == 0 {
+		return nil
+	}
+	var allowed []string
+	for _, rule := range allow {
+		allowed = append(allowed, rule.text)
+	}
+	for _, text := range parsed.commands {
+		i := slices.IndexFunc(allow, func(rule commandPattern) bool { return rule.match(text, true) })
+		if i < 0 {
+			return denied("%q is not allowed; allowed commands: %s", text, strings.Join(allowed, ", "))
+		}
+		slog.Info("exec command allowed", "session", caller.SessionKey, "command", text, "rule", allow[i].text)
+	}
+	return nil
+}
+
+// Describe summarises the policy for tool descriptions.
+func (p *CommandPolicy) Describe() string {
+	if p == nil || len(p.sets) == 0 && p.err == nil {
+		return ""
+	}
+	return " Commands are checked against an allow/deny policy, including every part of pipelines and substitutions; a denied command fails with the reason."
+}
@@ -2,61 +2,277 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
 )
 
 // ---- read_file ----
 
-type ReadFileTool struct{}
+// readFileMaxBytes is the default cap on the text read_file returns.
+const readFileMaxBytes = 64 * 1024
 
-func (ReadFileTool) Definition() provider.ToolDefinition {
+type ReadFileTool struct {
+	Policy   *PathPolicy // nil allows any path
+	MaxBytes int         // cap on returned text; 0 means readFileMaxBytes
+}
+
+func (t ReadFileTool) Definition() provider.ToolDefinition {
 	return provider.ToolDefinition{
-		Name:        "read_file",
-		Description: "Read the full contents of a file from the filesystem.",
+		Name: "read_file",
+		Description: fmt.Sprintf("Read a text file from the filesystem, optionally a range of lines (offset/limit) or its last lines (tail). "+
+			"Output is capped at %d bytes with a notice saying how to continue; binary files are summarised instead of shown.", t.maxBytes()) + t.Policy.Describe(),
 		InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
-    "path": {"type": "string", "description": "File path to read."}
+    "path":         {"type": "string", "description": "File path to read. Relative paths are resolved against the workspace."},
+    "offset":       {"type": "integer", "description": "First line to read, starting at 1."},
+    "limit":        {"type": "integer", "description": "Maximum number of lines to read."},
+    "tail":         {"type": "integer", "description": "Read the last N lines instead (ignores offset and limit)."},
+    "line_numbers": {"type": "boolean", "description": "Prefix each line with its number."},
+    "max_bytes":    {"type": "integer", "description": "Return at most this many bytes (can only lower the default cap)."}
   },
   "required": ["path"]
 }`),
 	}
 }
 
-func (ReadFileTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t ReadFileTool) maxBytes() int {
+	if t.MaxBytes > 0 {
+		return t.MaxBytes
+	}
+	return readFileMaxBytes
+}
+
+func (t ReadFileTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var args struct {
-		Path string `json:"path"`
+		Path        string `json:"path"`
+		Offset      int    `json:"offset"`
+		Limit       int    `json:"limit"`
+		Tail        int    `json:"tail"`
+		LineNumbers bool   `json:"line_numbers"`
+		MaxBytes    int    `json:"max_bytes"`
 	}
 	if err := json.Unmarshal(input, &args); err != nil {
 		return "", err
 	}
-	path := expandHome(args.Path)
-	data, err := os.ReadFile(path)
+	path, err := t.Policy.Check(ctx, args.Path, false)
+	if err != nil {
+		return "", fmt.Errorf("read_file: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("read_file: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
 	if err != nil {
 		return "", fmt.Errorf("read_file: %w", err)
 	}
-	return string(data), nil
+	if fi.IsDir() {
+		return "", fmt.Errorf("read_file: %s is a directory; use list_dir", args.Path)
+	}
+
+	head := make([]byte, 8192)
+	n, _ := io.ReadFull(f, head)
+	if isBinary(head[:n]) {
+		return fmt.Sprintf("%s is a binary file (%s, %d bytes); its contents are not shown. Use exec (e.g. file, xxd | head) to inspect it.",
+			args.Path, http.DetectContentType(head[:n]), fi.Size()), nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("read_file: %w", err)
+	}
+
+	maxBytes := t.maxBytes()
+	if args.MaxBytes > 0 && args.MaxBytes < maxBytes {
+		maxBytes = args.MaxBytes
+	}
+	return readLines(f, args.Offset, args.Limit, args.Tail, args.LineNumbers, maxBytes)
+}
+
+// readFileCountMax is how many lines read_file counts to report a file's
+// length once it has what it returns; longer files are reported as "N+".
+const readFileCountMax = 100_000
+
+// readLines returns lines of r: offset/limit select a range (offset counts
+// from 1), tail the last lines instead. At most maxBytes are returned, and no
+// more than that is held per line. A notice follows whenever the output is not
+// the whole file.
+func readLines(r io.Reader, offset, limit, tail int, numbers bool, maxBytes int) (string, error) {
+	offset = max(offset, 1)
+	format := func(n int, line string) string {
+		if numbers {
+			return fmt.Sprintf("%6d\t%s", n, line)
+		}
+		return line
+	}
+
+	type numbered struct {
+		n    int
+		text string
+		long bool // the line was longer than maxBytes and only its end is held
+	}
+	var picked []numbered
+	size, total := 0, 0
+	truncated, cut, counted := false, false, true
+	br := bufio.NewReader(r)
+	for {
+		head, end, n, ok, err := readLine(br, maxBytes)
+		if ok {
+			total++
+			switch {
+			case tail > 0:
+				// A line longer than maxBytes can only be shown cut; keep its end.
+				picked = append(picked, numbered{total, format(total, end), n > maxBytes})
+				if len(picked) > tail {
+					picked = picked[1:]
+				}
+			case truncated || total < offset || limit > 0 && total >= offset+limit:
+				if total >= readFileCountMax && len(picked) > 0 {
+					counted = false // stop counting; the rest is not needed
+				}
+			default:
+				text := format(total, head)
+				if size+len(text)+1 > maxBytes {
+					truncated = true
+					if len(picked) == 0 { // a single huge line: show its start
+						picked = append(picked, numbered{n: total, text: strings.ToValidUTF8(text[:min(maxBytes, len(text))], "")})
+						cut = true
+					}
+					break
+				}
+				picked = append(picked, numbered{n: total, text: text})
+				size += len(text) + 1
+			}
+		}
+		if err == io.EOF || !counted {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("read_file: %w", err)
+		}
+	}
+	if tail > 0 {
+		// Keep the newest lines that fit.
+		size = 0
+		for i := len(picked) - 1; i >= 0; i-- {
+			if text := picked[i].text; i == len(picked)-1 && (len(text) >= maxBytes || picked[i].long) {
+				// The last line alone does not fit: show its end.
+				if len(text) > maxBytes {
+					picked[i].text, cut = strings.ToValidUTF8(text[len(text)-maxBytes:], ""), true
+				}
+				if picked[i].long {
+					picked[i].text, cut = strings.ToValidUTF8(picked[i].text, ""), true
+				}
+				picked, truncated = picked[i:], i > 0
+				break
+			}
+			if size+len(picked[i].text)+1 > maxBytes {
+				picked, truncated = picked[i+1:], true
+				break
+			}
+			size += len(picked[i].text) + 1
+		}
+	}
+
+	if total == 0 {
+		return "(empty file)", nil
+	}
+	of := fmt.Sprint(total)
+	if !counted {
+		of += "+"
+	}
+	if len(picked) == 0 {
+		return fmt.Sprintf("[the file has %d lines; offset %d is past the end]", total, offset), nil
+	}
+	texts := make([]string, len(picked))
+	for i, l := range picked {
+		texts[i] = l.text
+	}
+	out := strings.Join(texts, "\n")
+	first, last := picked[0].n, picked[len(picked)-1].n
+	switch {
+	case first == 1 && last == total && counted && !truncated && !cut:
+		return out, nil
+	case tail > 0 && cut:
+		return out + fmt.Sprintf("\n\n[line %d of %d is longer than %d bytes; only its end is shown]", first, total, maxBytes), nil
+	case tail > 0 && truncated:
+		return out + fmt.Sprintf("\n\n[last lines %d-%d of %d; earlier lines would exceed %d bytes]", first, last, total, maxBytes), nil
+	case tail > 0:
+		return out + fmt.Sprintf("\n\n[last lines %d-%d of %d]", first, last, total), nil
+	case cut:
+		return out + fmt.Sprintf("\n\n[line %d of %s is longer than %d bytes and was cut; continue with offset=%d]", first, of, maxBytes, last+1), nil
+	case truncated:
+		return out + fmt.Sprintf("\n\n[truncated at %d bytes: lines %d-%d of %s shown; continue with offset=%d]", maxBytes, first, last, of, last+1), nil
+	case last < total || !counted:
+		return out + fmt.Sprintf("\n\n[lines %d-%d of %s; continue with offset=%d]", first, last, of, last+1), nil
+	}
+	return out + fmt.Sprintf("\n\n[lines %d-%d of %d]", first, last, total), nil
+}
+
+// readLine reads one line from br without its newline, holding at most limit
+// bytes of its start (head) and of its end; n is its full length. ok is false
+// at the end of input.
+func readLine(br *bufio.Reader, limit int) (head, end string, n int, ok bool, err error) {
+	var h, e []byte
+	for {
+		chunk, err := br.ReadSlice('\n')
+		newline := len(chunk) > 0 && chunk[len(chunk)-1] == '\n'
+		if newline {
+			chunk = chunk[:len(chunk)-1]
+		}
+		ok = ok || newline || len(chunk) > 0
+		n += len(chunk)
+		if len(h) < limit {
+			h = append(h, chunk[:min(limit-len(h), len(chunk))]...)
+		}
+		e = append(e, chunk...)
+		if over := len(e) - limit; over > 0 {
+			e = append(e[:0], e[over:]...)
+		}
+		if err != bufio.ErrBufferFull {
+			return string(h), string(e), n, ok, err
+		}
+	}
+}
+
+// isBinary reports whether the start of a file looks like binary data: it
+// contains a NUL byte or is not valid UTF-8.
+func isBinary(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	// Ignore a rune cut off at the end of the sample.
+	for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	return !utf8.Valid(head)
 }
 
 // ---- write_file ----
 
-type WriteFileTool struct{}
+type WriteFileTool struct {
+	Policy *PathPolicy // nil allows any path
+}
 
-func (WriteFileTool) Definition() provider.ToolDefinition {
+func (t WriteFileTool) Definition() provider.ToolDefinition {
 	return provider.ToolDefinition{
 		Name:        "write_file",
-		Description: "Write content to a file, creating it and any parent directories as needed.",
+		Description: "Write content to a file, creating it and any parent directories as needed." + t.Policy.Describe(),
 		InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
-    "path":    {"type": "string", "description": "File path to write."},
+    "path":    {"type": "string", "description": "File path to write. Relative paths are resolved against the workspace."},
     "content": {"type": "string", "description": "Content to write."}
   },
   "required": ["path", "content"]
@@ -64,7 +280,7 @@ func (WriteFileTool) Definition() provider.ToolDefinition {
 	}
 }
 
-func (WriteFileTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t WriteFileTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var args struct {
 		Path    string `json:"path"`
 		Content string `json:"content"`
@@ -72,7 +288,10 @@ func (WriteFileTool) Execute(_ context.Context, input json.RawMessage) (string,
 	if err := json.Unmarshal(input, &args); err != nil {
 		return "", err
 	}
-	path := expandHome(args.Path)
+	path, err := t.Policy.Check(ctx, args.Path, true)
+	if err != nil {
+		return "", fmt.Errorf("write_file: %w", err)
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return "", fmt.Errorf("write_file mkdir: %w", err)
 	}
@@ -84,16 +303,18 @@ func (WriteFileTool) Execute(_ context.Context, input json.RawMessage) (string,
 
 // ---- edit_file ----
 
-type EditFileTool struct{}
+type EditFileTool struct {
+	Policy *PathPolicy // nil allows any path
+}
 
-func (EditFileTool) Definition() provider.ToolDefinition {
+func (t EditFileTool) Definition() provider.ToolDefinition {
 	return provider.ToolDefinition{
 		Name:        "edit_file",
-		Description: "Replace the first occurrence of old_text with new_text in a file.",
+		Description: "Replace the first occurrence of old_text with new_text in a file." + t.Policy.Describe(),
 		InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
-    "path":     {"type": "string", "description": "File path to edit."},
+    "path":     {"type": "string", "description": "File path to edit. Relative paths are resolved against the workspace."},
     "old_text": {"type": "string", "description": "Exact text to find."},
     "new_text": {"type": "string", "description": "Replacement text."}
   },
@@ -102,7 +323,7 @@ func (EditFileTool) Definition() provider.ToolDefinition {
 	}
 }
 
-func (EditFileTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t EditFileTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var args struct {
 		Path    string `json:"path"`
 		OldText string `json:"old_text"`
@@ -111,7 +332,10 @@ func (EditFileTool) Execute(_ context.Context, input json.RawMessage) (string, e
 	if err := json.Unmarshal(input, &args); err != nil {
 		return "", err
 	}
-	path := expandHome(args.Path)
+	path, err := t.Policy.Check(ctx, args.Path, true)
+	if err != nil {
+		return "", fmt.Errorf("edit_file: %w", err)
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("edit_file read: %w", err)
@@ -129,30 +353,35 @@ func (EditFileTool) Execute(_ context.Context, input json.RawMessage) (string, e
 
 // ---- list_dir ----
 
-type ListDirTool struct{}
+type ListDirTool struct {
+	Policy *PathPolicy // nil allows any path
+}
 
-func (ListDirTool) Definition() provider.ToolDefinition {
+func (t ListDirTool) Definition() provider.ToolDefinition {
 	return provider.ToolDefinition{
 		Name:        "list_dir",
-		Description: "List files and directories in a given path.",
+		Description: "List files and directories in a given path." + t.Policy.Describe(),
 		InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
-    "path": {"type": "string", "description": "Directory path to list."}
+    "path": {"type": "string", "description": "Directory path to list. Relative paths are resolved against the workspace."}
   },
   "required": ["path"]
 }`),
 	}
 }
 
-func (ListDirTool) Execute(_ context.Context, input json.RawMessage) (string, error) {
+func (t ListDirTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
 	var args struct {
 		Path string `json:"path"`
 	}
 	if err := json.Unmarshal(input, &args); err != nil {
 		return "", err
 	}
-	path := expandHome(args.Path)
+	path, err := t.Policy.Check(ctx, args.Path, false)
+	if err != nil {
+		return "", fmt.Errorf("list_dir: %w", err)
+	}
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		return "", fmt.Errorf("list_dir: %w", err)
@@ -0,0 +1,71 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	five := "one\ntwo\nthree\nfour\nfive\n"
+	tests := []struct {
+		name                string
+		src                 string
+		offset, limit, tail int
+		numbers             bool
+		maxBytes            int
+		want                string
+	}{
+		{"whole file", five, 0, 0, 0, false, 100, "one\ntwo\nthree\nfour\nfive"},
+		{"no final newline", "a\nb", 0, 0, 0, false, 100, "a\nb"},
+		{"empty", "", 0, 0, 0, false, 100, "(empty file)"},
+		{"numbers", "a\nb\n", 0, 0, 0, true, 100, "     1\ta\n     2\tb"},
+		{"offset", five, 4, 0, 0, false, 100, "four\nfive\n\n[lines 4-5 of 5]"},
+		{"limit", five, 0, 2, 0, false, 100, "one\ntwo\n\n[lines 1-2 of 5; continue with offset=3]"},
+		{"offset and limit", five, 2, 2, 0, true, 100, "     2\ttwo\n     3\tthree\n\n[lines 2-3 of 5; continue with offset=4]"},
+		{"past the end", five, 9, 0, 0, false, 100, "[the file has 5 lines; offset 9 is past the end]"},
+		{"tail", five, 0, 0, 2, false, 100, "four\nfive\n\n[last lines 4-5 of 5]"},
+		{"tail longer than file", five, 0, 0, 9, false, 100, "one\ntwo\nthree\nfour\nfive"},
+		{"tail over the cap", five, 0, 0, 5, false, 10, "four\nfive\n\n[last lines 4-5 of 5; earlier lines would exceed 10 bytes]"},
+		{"tail of a long line", "short\n" + strings.Repeat("x", 20) + "yz\n", 0, 0, 1, false, 4, "xxyz\n\n[line 2 of 2 is longer than 4 bytes; only its end is shown]"},
+		{"tail of a long line only", strings.Repeat("x", 20) + "é", 0, 0, 1, false, 3, "xé\n\n[line 1 of 1 is longer than 3 bytes; only its end is shown]"},
+		{"tail of a line at the cap", "ab\nwxyz\n", 0, 0, 2, false, 4, "wxyz\n\n[last lines 2-2 of 2; earlier lines would exceed 4 bytes]"},
+		{"byte cap", five, 0, 0, 0, false, 10, "one\ntwo\n\n[truncated at 10 bytes: lines 1-2 of 5 shown; continue with offset=3]"},
+		{"long line", strings.Repeat("y", 20) + "\nz\n", 0, 0, 0, false, 5, "yyyyy\n\n[line 1 of 2 is longer than 5 bytes and was cut; continue with offset=2]"},
+		{"line over the read buffer", strings.Repeat("y", 10000) + "\nz\n", 0, 0, 0, false, 3, "yyy\n\n[line 1 of 2 is longer than 3 bytes and was cut; continue with offset=2]"},
+		{"tail of a line over the read buffer", "a\n" + strings.Repeat("y", 10000) + "z\n", 0, 0, 1, false, 3, "yyz\n\n[line 2 of 2 is longer than 3 bytes; only its end is shown]"},
+		{"counting stops", strings.Repeat("l\n", readFileCountMax+10), 0, 1, 0, false, 100, fmt.Sprintf("l\n\n[lines 1-1 of %d+; continue with offset=2]", readFileCountMax)},
+		{"long line cut inside a rune", "abcé\n", 0, 0, 0, false, 4, "abc\n\n[line 1 of 1 is longer than 4 bytes and was cut; continue with offset=2]"},
+	}
+	for _, tt := range tests {
+		got, err := readLines(strings.NewReader(tt.src), tt.offset, tt.limit, tt.tail, tt.numbers, tt.maxBytes)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		head []byte
+		want bool
+	}{
+		{[]byte("plain text\n"), false},
+		{[]byte("naïve ünïcode"), false},
+		{[]byte("cut rune \xc3"), false},
+		{[]byte{}, false},
+		{[]byte("nul\x00byte"), true},
+		{[]byte("\x89PNG\r\n\x1a\n"), true},
+		{[]byte("latin-1 caf\xe9 au lait"), true},
+	}
+	for _, tt := range tests {
+		if got := isBinary(tt.head); got != tt.want {
+			t.Errorf("isBinary(%q) = %v, want %v", tt.head, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// MemorySearchTool searches long-term memory and consolidated history.
+type MemorySearchTool struct {
+	searchFunc func(ctx context.Context, query, source string, limit int) (string, error)
+}
+
+// NewMemorySearchTool creates a MemorySearchTool.
+func NewMemorySearchTool(searchFunc func(ctx context.Context, query, source string, limit int) (string, error)) MemorySearchTool {
+	return MemorySearchTool{searchFunc: searchFunc}
+}
+
+func (t MemorySearchTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "memory_search",
+		Description: "Search long-term memory (MEMORY.md sections) and summaries of earlier conversations (journal entries and weekly/monthly rollups), ranked by relevance. Use it when the history shown in the system prompt is not enough.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "query":  {"type": "string",  "description": "Words to search for."},
+    "source": {"type": "string",  "enum": ["history", "memory"], "description": "Search only the journals or only MEMORY.md (default both)."},
+    "limit":  {"type": "integer", "description": "Maximum results (default 5)."}
+  },
+  "required": ["query"]
+}`),
+	}
+}
+
+func (t MemorySearchTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Source string `json:"source"`
+		Limit  int    `json:"limit"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.Query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = 5
+	}
+	return t.searchFunc(ctx, args.Query, args.Source, args.Limit)
+}
+
+// MemoryRememberTool stores a fact in long-term memory.
+type MemoryRememberTool struct {
+	rememberFunc func(ctx context.Context, category, content string) (string, error)
+}
+
+// NewMemoryRememberTool creates a MemoryRememberTool.
+func NewMemoryRememberTool(rememberFunc func(ctx context.Context, category, content string) (string, error)) MemoryRememberTool {
+	return MemoryRememberTool{rememberFunc: rememberFunc}
+}
+
+func (t MemoryRememberTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "memory_remember",
+		Description: "Save a lasting fact to long-term memory (MEMORY.md), e.g. a preference, a person, or a project detail. Keep each fact short and self-contained; to change a fact, forget the old entry and remember the new one.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "content":  {"type": "string", "description": "The fact to remember, one sentence."},
+    "category": {"type": "string", "description": "Short lowercase category such as preferences, people, projects (default general)."}
+  },
+  "required": ["content"]
+}`),
+	}
+}
+
+func (t MemoryRememberTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Content  string `json:"content"`
+		Category string `json:"category"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.Content == "" {
+		return "", fmt.Errorf("content is required")
+	}
+	return t.rememberFunc(ctx, args.Category, args.Content)
+}
+
+// MemoryForgetTool removes a fact from long-term memory.
+type MemoryForgetTool struct {
+	forgetFunc func(ctx context.Context, id int) (string, error)
+}
+
+// NewMemoryForgetTool creates a MemoryForgetTool.
+func NewMemoryForgetTool(forgetFunc func(ctx context.Context, id int) (string, error)) MemoryForgetTool {
+	return MemoryForgetTool{forgetFunc: forgetFunc}
+}
+
+func (t MemoryForgetTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "memory_forget",
+		Description: "Remove an entry from long-term memory by its ID, shown as (#ID) in the memory section or by memory_list.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer", "description": "ID of the entry to remove."}
+  },
+  "required": ["id"]
+}`),
+	}
+}
+
+func (t MemoryForgetTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.ID <= 0 {
+		return "", fmt.Errorf("id is required")
+	}
+	return t.forgetFunc(ctx, args.ID)
+}
+
+// MemoryListTool lists long-term memory entries with their metadata.
+type MemoryListTool struct {
+	listFunc func(ctx context.Context, category string) (string, error)
+}
+
+// NewMemoryListTool creates a MemoryListTool.
+func NewMemoryListTool(listFunc func(ctx context.Context, category string) (string, error)) MemoryListTool {
+	return MemoryListTool{listFunc: listFunc}
+}
+
+func (t MemoryListTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "memory_list",
+		Description: "List long-term memory entries with their ID, category, last update and the session that wrote them.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "category": {"type": "string", "description": "Only list this category."}
+  }
+}`),
+	}
+}
+
+func (t MemoryListTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Category string `json:"category"`
+	}
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &args); err != nil {
+			return "", err
+		}
+	}
+	return t.listFunc(ctx, args.Category)
+}
+
+// JournalReadTool reads the dated journals of past conversations.
+type JournalReadTool struct {
+	readFunc func(ctx context.Context, kind, from, to string) (string, error)
+}
+
+// NewJournalReadTool creates a JournalReadTool.
+func NewJournalReadTool(readFunc func(ctx context.Context, kind, from, to string) (string, error)) JournalReadTool {
+	return JournalReadTool{readFunc: readFunc}
+}
+
+func (t JournalReadTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "journal_read",
+		Description: "Read the journal of summarised conversations for a date range: daily entries, or weekly/monthly rollups for longer spans.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "from": {"type": "string", "description": "First day (YYYY-MM-DD); default today."},
+    "to":   {"type": "string", "description": "Last day (YYYY-MM-DD); default same as from."},
+    "kind": {"type": "string", "enum": ["daily", "weekly", "monthly"], "description": "Journal kind (default daily)."}
+  }
+}`),
+	}
+}
+
+func (t JournalReadTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Kind string `json:"kind"`
+	}
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &args); err != nil {
+			return "", err
+		}
+	}
+	if args.Kind == "" {
+		args.Kind = "daily"
+	}
+	return t.readFunc(ctx, args.Kind, args.From, args.To)
+}
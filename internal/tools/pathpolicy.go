@@ -0,0 +1,208 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PathPolicy restricts which files the fs tools may touch. Paths are checked
+// after ~ and relative paths are expanded against the caller's workspace and
+// symlinks are resolved, so a link inside the workspace cannot reach outside it.
+// A nil *PathPolicy allows everything.
+type PathPolicy struct {
+	Allowed  []string // read-write roots; empty means the caller's workspace
+	ReadOnly []string // roots that may be read but not written
+	Denied   []string // globs that are never accessible, e.g. "*.pem" or "**/.git/**"
+}
+
+// NewPathPolicy creates a PathPolicy. Relative roots are taken relative to the
+// caller's workspace when checked.
+func NewPathPolicy(allowed, readOnly, denied []string) *PathPolicy {
+	return &PathPolicy{Allowed: allowed, ReadOnly: readOnly, Denied: denied}
+}
+
+// Check resolves path for the caller and returns it if the policy permits
+// reading it, or writing it when write is set.
+func (p *PathPolicy) Check(ctx context.Context, path string, write bool) (string, error) {
+	resolved := resolvePath(ctx, path)
+	if p == nil {
+		return resolved, nil
+	}
+	real, err := realPath(resolved)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+
+	for _, g := range p.Denied {
+		if matchGlob(g, real) {
+			return "", fmt.Errorf("access denied: %s matches the denied pattern %q", real, g)
+		}
+	}
+
+	ws := CallerFrom(ctx).Workspace
+	allowed := p.roots(p.Allowed, ws)
+	if len(p.Allowed) == 0 && ws != "" {
+		allowed = p.roots([]string{ws}, ws)
+	}
+	for _, root := range allowed {
+		if within(real, root) {
+			return real, nil
+		}
+	}
+	readOnly := p.roots(p.ReadOnly, ws)
+	for _, root := range readOnly {
+		if within(real, root) {
+			if write {
+				return "", fmt.Errorf("access denied: %s is under the read-only root %s; write inside %s instead", real, root, strings.Join(allowed, ", "))
+			}
+			return real, nil
+		}
+	}
+	roots := append(append([]string{}, allowed...), readOnly...)
+	if len(roots) == 0 {
+		return "", fmt.Errorf("access denied: no file roots are configured")
+	}
+	return "", fmt.Errorf("access denied: %s is outside the allowed roots (%s); use a path inside them, relative paths resolve against the workspace", real, strings.Join(roots, ", "))
+}
+
+// Describe summarises the policy for tool descriptions.
+func (p *PathPolicy) Describe() string {
+	if p == nil {
+		return ""
+	}
+	allowed := "the workspace"
+	if len(p.Allowed) > 0 {
+		allowed = strings.Join(p.Allowed, ", ")
+	}
+	s := " Access is limited to " + allowed
+	if len(p.ReadOnly) > 0 {
+		s += " (read-only: " + strings.Join(p.ReadOnly, ", ") + ")"
+	}
+	return s + "."
+}
+
+// roots expands and resolves configured roots; relative ones are relative to ws.
+func (p *PathPolicy) roots(list []string, ws string) []string {
+	var out []string
+	for _, r := range list {
+		r = expandHome(r)
+		if !filepath.IsAbs(r) {
+			if ws == "" {
+				continue
+			}
+			r = filepath.Join(ws, r)
+		}
+		if real, err := realPath(r); err == nil {
+			r = real
+		}
+		out = append(out, filepath.Clean(r))
+	}
+	return out
+}
+
+// ResolveWithin resolves name, a path relative to root, and returns its real
+// path. Absolute names and names that lead outside root, through ".." or a
+// symlink, are rejected.
+func ResolveWithin(root, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("%s is not relative to %s", name, root)
+	}
+	realRoot, err := realPath(root)
+	if err != nil {
+		return "", err
+	}
+	real, err := realPath(filepath.Join(root, name))
+	if err != nil {
+		return "", err
+	}
+	if !within(real, realRoot) {
+		return "", fmt.Errorf("%s is outside %s", name, root)
+	}
+	return real, nil
+}
+
+// realPath resolves symlinks in path. For a path that does not exist yet, the
+// deepest existing ancestor is resolved and the rest appended.
+func realPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+func within(path, root string) bool {
+	if path == root {
+		return true
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// matchGlob matches path against a glob. Patterns without a slash match any
+// single path element ("*.pem", ".env"); others match the whole path, where
+// "**" spans directories.
+func matchGlob(pattern, path string) bool {
+	pattern = expandHome(pattern)
+	if !strings.Contains(pattern, "/") {
+		for _, elem := range strings.Split(path, string(os.PathSeparator)) {
+			if ok, _ := filepath.Match(pattern, elem); ok {
+				return true
+			}
+		}
+		return false
+	}
+	re, err := globRegexp(pattern)
+	return err == nil && re.MatchString(path)
+}
+
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !strings.HasPrefix(pattern, "/") {
+		sb.WriteString("(?:.*/)?") // relative patterns may start at any directory
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,235 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errShellExited is returned when the shell quits, e.g. after `exit`.
+var errShellExited = errors.New("the shell exited")
+
+// ShellPool keeps one long-lived bash per chat session, so the working
+// directory, exported variables and activated virtualenvs survive between
+// exec calls.
+type ShellPool struct {
+	Sandbox *Sandbox // when set, shells run inside it
+
+	mu     sync.Mutex
+	shells map[string]*persistentShell // session key -> shell
+}
+
+// NewShellPool creates an empty ShellPool.
+func NewShellPool(sandbox *Sandbox) *ShellPool {
+	return &ShellPool{Sandbox: sandbox, shells: make(map[string]*persistentShell)}
+}
+
+// persistentShell is a bash reading commands from a pipe. Each command is
+// followed by a marker line carrying its exit status; the random nonce keeps
+// command output from faking it.
+type persistentShell struct {
+	mu       sync.Mutex // one command at a time
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string // stdout and stderr, line by line; closed when the shell exits
+	killed   chan struct{}
+	killOnce sync.Once
+	marker   string
+	lastUsed time.Time
+}
+
+func (p *ShellPool) get(key, workspace string) (*persistentShell, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sh := p.shells[key]; sh != nil {
+		return sh, nil
+	}
+	sh, err := p.start(workspace)
+	if err != nil {
+		return nil, err
+	}
+	p.shells[key] = sh
+	return sh, nil
+}
+
+func (p *ShellPool) start(workspace string) (*persistentShell, error) {
+	var cmd *exec.Cmd
+	if p.Sandbox != nil {
+		var err error
+		// The shell outlives any one request, so it gets its own context.
+		if cmd, err = p.Sandbox.Command(context.Background(), "exec bash --noprofile --norc", workspace, workspace); err != nil {
+			return nil, err
+		}
+	} else {
+		cmd = exec.Command("bash", "--noprofile", "--norc")
+		cmd.Dir = workspace
+		setProcessGroup(cmd)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout, cmd.Stderr = w, w
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	w.Close()
+
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	sh := &persistentShell{
+		cmd:      cmd,
+		stdin:    stdin,
+		lines:    make(chan string, 256),
+		killed:   make(chan struct{}),
+		marker:   "__miniclaw_done_" + hex.EncodeToString(nonce) + "__ ",
+		lastUsed: time.Now(),
+	}
+	go func() {
+		defer close(sh.lines)
+		defer r.Close()
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if line != "" {
+				select {
+				case sh.lines <- line:
+				case <-sh.killed: // nobody is reading any more
+				}
+			}
+			if err != nil {
+				cmd.Wait()
+				return
+			}
+		}
+	}()
+	return sh, nil
+}
+
+// Run runs command in the session's shell, starting one in workspace if
+// needed, and returns its combined output and exit status. On timeout the
+// shell is killed and the next call starts a fresh one.
+func (p *ShellPool) Run(ctx context.Context, key, workspace, command string, timeout time.Duration) (string, int, error) {
+	sh, err := p.get(key, workspace)
+	if err != nil {
+		return "", 0, err
+	}
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.lastUsed = time.Now()
+
+	// The command travels base64-encoded so its quoting and heredocs cannot
+	// interfere with the marker; stdin is closed so it cannot eat later input.
+	script := fmt.Sprintf("eval \"$(printf %%s '%s' | base64 -d)\" </dev/null\nprintf '\\n%s%%d\\n' $?\n",
+		base64.StdEncoding.EncodeToString([]byte(command)), sh.marker)
+	if _, err := io.WriteString(sh.stdin, script); err != nil {
+		p.drop(key, sh)
+		return "", 0, errShellExited
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	var out strings.Builder
+	for {
+		select {
+		case line, ok := <-sh.lines:
+			if !ok {
+				p.drop(key, sh)
+				return out.String(), 0, errShellExited
+			}
+			if rest, found := strings.CutPrefix(line, sh.marker); found {
+				code, _ := strconv.Atoi(strings.TrimSpace(rest))
+				sh.lastUsed = time.Now()
+				// Drop the newline printed before the marker.
+				return strings.TrimSuffix(out.String(), "\n"), code, nil
+			}
+			out.WriteString(line)
+		case <-timer.C:
+			p.drop(key, sh)
+			return out.String(), 0, context.DeadlineExceeded
+		case <-ctx.Done():
+			p.drop(key, sh)
+			return out.String(), 0, ctx.Err()
+		}
+	}
+}
+
+// drop kills sh and forgets it if it is still the session's shell.
+func (p *ShellPool) drop(key string, sh *persistentShell) {
+	p.mu.Lock()
+	if p.shells[key] == sh {
+		delete(p.shells, key)
+	}
+	p.mu.Unlock()
+	sh.kill()
+}
+
+func (sh *persistentShell) kill() {
+	sh.killOnce.Do(func() { close(sh.killed) })
+	sh.stdin.Close()
+	// Without a sandbox the shell leads its own process group; in one,
+	// killing PID 1 of the namespace takes everything else with it.
+	killProcessGroup(sh.cmd)
+}
+
+// Reset kills the session's shell, if any; the next command starts a fresh one.
+// It reports whether there was a shell.
+func (p *ShellPool) Reset(key string) bool {
+	p.mu.Lock()
+	sh := p.shells[key]
+	delete(p.shells, key)
+	p.mu.Unlock()
+	if sh == nil {
+		return false
+	}
+	sh.kill()
+	return true
+}
+
+// Reap kills shells unused for longer than idle and returns how many it killed.
+// Shells running a command are left alone.
+func (p *ShellPool) Reap(idle time.Duration) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for key, sh := range p.shells {
+		if !sh.mu.TryLock() {
+			continue
+		}
+		if time.Since(sh.lastUsed) > idle {
+			delete(p.shells, key)
+			sh.kill()
+			n++
+		}
+		sh.mu.Unlock()
+	}
+	return n
+}
+
+// Close kills every shell.
+func (p *ShellPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, sh := range p.shells {
+		delete(p.shells, key)
+		sh.kill()
+	}
+}
@@ -0,0 +1,169 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// --- process_start ---
+
+// ProcessStartTool starts a background process.
+type ProcessStartTool struct {
+	procs *ProcessManager
+}
+
+// NewProcessStartTool creates a ProcessStartTool.
+func NewProcessStartTool(procs *ProcessManager) ProcessStartTool {
+	return ProcessStartTool{procs: procs}
+}
+
+func (t ProcessStartTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "process_start",
+		Description: "Start a long-running shell command in the background (builds, downloads, dev servers) and return its id right away. Poll it with process_output; the chat is notified when it exits." + t.procs.Sandbox.Describe() + t.procs.Policy.Describe(),
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "command": {"type": "string", "description": "Shell command to run."},
+    "workdir": {"type": "string", "description": "Working directory (optional, defaults to the workspace)."}
+  },
+  "required": ["command"]
+}`),
+	}
+}
+
+func (t ProcessStartTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Command string `json:"command"`
+		Workdir string `json:"workdir"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.Command == "" {
+		return "", fmt.Errorf("process_start: command is required")
+	}
+	dir := CallerFrom(ctx).Workspace
+	if args.Workdir != "" {
+		dir = resolvePath(ctx, args.Workdir)
+	}
+	info, err := t.procs.Start(ctx, args.Command, dir)
+	if err != nil {
+		return "", fmt.Errorf("process_start: %w", err)
+	}
+	return fmt.Sprintf("Started %s. Output is logged to %s.", info.ID, info.LogPath), nil
+}
+
+// --- process_output ---
+
+// ProcessOutputTool returns a background process's status and output.
+type ProcessOutputTool struct {
+	procs *ProcessManager
+}
+
+// NewProcessOutputTool creates a ProcessOutputTool.
+func NewProcessOutputTool(procs *ProcessManager) ProcessOutputTool {
+	return ProcessOutputTool{procs: procs}
+}
+
+func (t ProcessOutputTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "process_output",
+		Description: "Get a background process's status and the output written since the last call, or its last lines with tail.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "id":   {"type": "string", "description": "Process id, e.g. p3."},
+    "tail": {"type": "integer", "description": "Return the last N lines instead of the new output."}
+  },
+  "required": ["id"]
+}`),
+	}
+}
+
+func (t ProcessOutputTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		ID   string `json:"id"`
+		Tail int    `json:"tail"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	info, out, err := t.procs.Output(ctx, args.ID, args.Tail)
+	if err != nil {
+		return "", fmt.Errorf("process_output: %w", err)
+	}
+	if out == "" {
+		out = "(no new output)"
+	}
+	return fmt.Sprintf("%s %s\n%s", info.ID, processStatus(info, time.Now()), out), nil
+}
+
+// --- process_list ---
+
+// ProcessListTool lists the chat's background processes.
+type ProcessListTool struct {
+	procs *ProcessManager
+}
+
+// NewProcessListTool creates a ProcessListTool.
+func NewProcessListTool(procs *ProcessManager) ProcessListTool {
+	return ProcessListTool{procs: procs}
+}
+
+func (t ProcessListTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "process_list",
+		Description: "List the background processes started in this chat, running and recently exited.",
+		InputSchema: json.RawMessage(`{"type": "object", "properties": {}}`),
+	}
+}
+
+func (t ProcessListTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	return FormatProcesses(t.procs.List(ctx), time.Now()), nil
+}
+
+// --- process_kill ---
+
+// ProcessKillTool stops a background process.
+type ProcessKillTool struct {
+	procs *ProcessManager
+}
+
+// NewProcessKillTool creates a ProcessKillTool.
+func NewProcessKillTool(procs *ProcessManager) ProcessKillTool {
+	return ProcessKillTool{procs: procs}
+}
+
+func (t ProcessKillTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "process_kill",
+		Description: "Stop a background process and its children: SIGTERM, then SIGKILL after a few seconds.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "id": {"type": "string", "description": "Process id, e.g. p3."}
+  },
+  "required": ["id"]
+}`),
+	}
+}
+
+func (t ProcessKillTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	info, err := t.procs.Kill(ctx, args.ID, 5*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("process_kill: %w", err)
+	}
+	return fmt.Sprintf("%s %s", info.ID, processStatus(info, time.Now())), nil
+}
@@ -0,0 +1,19 @@
+// MIT License - Copyright (c) 2026 yosebyte
+
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+func terminateProcessGroup(cmd *exec.Cmd) {
+	killProcessGroup(cmd)
+}
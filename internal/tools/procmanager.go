@@ -0,0 +1,361 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of the background process manager.
+const (
+	processBufferSize = 64 * 1024        // output kept in memory per process
+	processLogMax     = 10 * 1024 * 1024 // log file bytes before logging stops
+	processMaxRunning = 8                // running processes per session
+	processKeepExited = 20               // exited processes remembered per session
+)
+
+// ProcessInfo describes a background process.
+type ProcessInfo struct {
+	ID         string
+	SessionKey string
+	ChatID     string
+	Command    string
+	Dir        string
+	LogPath    string
+	Started    time.Time
+	Ended      time.Time // zero while running
+	ExitCode   int
+	Killed     bool // stopped by process_kill or a session reset
+}
+
+// Running reports whether the process has not exited yet.
+func (p ProcessInfo) Running() bool {
+	return p.Ended.IsZero()
+}
+
+// ProcessManager runs long-lived commands in the background for chat
+// sessions. Output goes to a rolling in-memory buffer and to a log file under
+// the workspace's processes/ directory.
+type ProcessManager struct {
+	Sandbox *Sandbox                  // when set, processes run inside it
+	Policy  *CommandPolicy            // commands must pass it to start
+	OnExit  func(ProcessInfo, string) // called with the info and the output tail when a process exits
+
+	mu     sync.Mutex
+	nextID int
+	procs  map[string]*managedProcess
+}
+
+// NewProcessManager creates a ProcessManager.
+func NewProcessManager(sandbox *Sandbox) *ProcessManager {
+	return &ProcessManager{Sandbox: sandbox, procs: make(map[string]*managedProcess)}
+}
+
+type managedProcess struct {
+	mu     sync.Mutex
+	info   ProcessInfo
+	cmd    *exec.Cmd
+	log    *os.File
+	logged int64
+	buf    []byte // the last processBufferSize bytes of output
+	total  int64  // bytes of output so far
+	read   int64  // total at the last poll
+	done   chan struct{}
+}
+
+// Write records output in the buffer and the log file.
+func (p *managedProcess) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += int64(len(b))
+	p.buf = append(p.buf, b...)
+	if over := len(p.buf) - processBufferSize; over > 0 {
+		p.buf = append(p.buf[:0], p.buf[over:]...)
+	}
+	if p.log != nil && p.logged < processLogMax {
+		n, _ := p.log.Write(b)
+		p.logged += int64(n)
+		if p.logged >= processLogMax {
+			p.log.WriteString("\n[log truncated at 10 MB]\n")
+		}
+	}
+	return len(b), nil
+}
+
+// Start runs command in dir for the caller's session.
+func (m *ProcessManager) Start(ctx context.Context, command, dir string) (ProcessInfo, error) {
+	caller := CallerFrom(ctx)
+	if err := m.Policy.Check(ctx, command); err != nil {
+		return ProcessInfo{}, err
+	}
+	m.mu.Lock()
+	running := 0
+	for _, p := range m.procs {
+		if p.info.SessionKey == caller.SessionKey && p.snapshot().Running() {
+			running++
+		}
+	}
+	if running >= processMaxRunning {
+		m.mu.Unlock()
+		return ProcessInfo{}, fmt.Errorf("%d processes are already running in this chat; stop one with process_kill first", running)
+	}
+	m.nextID++
+	id := fmt.Sprintf("p%d", m.nextID)
+	m.mu.Unlock()
+
+	var cmd *exec.Cmd
+	if m.Sandbox != nil {
+		var err error
+		// Processes outlive the request that started them.
+		if cmd, err = m.Sandbox.Command(context.Background(), command, dir, caller.Workspace); err != nil {
+			return ProcessInfo{}, err
+		}
+	} else {
+		cmd = exec.Command("bash", "-c", command)
+		cmd.Dir = dir
+		setProcessGroup(cmd)
+	}
+
+	logDir := filepath.Join(caller.Workspace, "processes")
+	// Output may hold secrets; keep it to the gateway's user.
+	if err := os.MkdirAll(logDir, 0700); err != nil {
+		return ProcessInfo{}, err
+	}
+	logPath := filepath.Join(logDir, fmt.Sprintf("%s-%s.log", time.Now().Format("20060102-150405"), id))
+	log, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	p := &managedProcess{
+		info: ProcessInfo{
+			ID: id, SessionKey: caller.SessionKey, ChatID: caller.ChatID,
+			Command: command, Dir: dir, LogPath: logPath, Started: time.Now(),
+		},
+		cmd:  cmd,
+		log:  log,
+		done: make(chan struct{}),
+	}
+	cmd.Stdout, cmd.Stderr = p, p
+	// Don't wait forever for output from children that outlive the process.
+	cmd.WaitDelay = 5 * time.Second
+	if err := cmd.Start(); err != nil {
+		log.Close()
+		os.Remove(logPath)
+		return ProcessInfo{}, err
+	}
+
+	m.mu.Lock()
+	m.procs[id] = p
+	m.pruneLocked(caller.SessionKey)
+	m.mu.Unlock()
+
+	go m.wait(p)
+	return p.snapshot(), nil
+}
+
+func (m *ProcessManager) wait(p *managedProcess) {
+	err := p.cmd.Wait()
+	p.mu.Lock()
+	p.info.Ended = time.Now()
+	p.info.ExitCode = p.cmd.ProcessState.ExitCode()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		fmt.Fprintf(p.log, "\n[wait: %v]\n", err)
+	}
+	p.log.Close()
+	p.log = nil
+	info := p.info
+	tail := string(p.buf)
+	p.mu.Unlock()
+	close(p.done)
+	if m.OnExit != nil {
+		m.OnExit(info, tail)
+	}
+}
+
+func (p *managedProcess) snapshot() ProcessInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.info
+}
+
+// pruneLocked forgets the oldest exited processes of a session beyond
+// processKeepExited and deletes their log files.
+func (m *ProcessManager) pruneLocked(sessionKey string) {
+	var exited []*managedProcess
+	for _, p := range m.procs {
+		if info := p.snapshot(); info.SessionKey == sessionKey && !info.Running() {
+			exited = append(exited, p)
+		}
+	}
+	if len(exited) <= processKeepExited {
+		return
+	}
+	sort.Slice(exited, func(i, j int) bool { return exited[i].info.Ended.Before(exited[j].info.Ended) })
+	for _, p := range exited[:len(exited)-processKeepExited] {
+		delete(m.procs, p.info.ID)
+		os.Remove(p.info.LogPath)
+	}
+}
+
+// get returns the caller's process with the given id.
+func (m *ProcessManager) get(ctx context.Context, id string) (*managedProcess, error) {
+	m.mu.Lock()
+	p := m.procs[strings.TrimSpace(id)]
+	m.mu.Unlock()
+	if p == nil || p.snapshot().SessionKey != CallerFrom(ctx).SessionKey {
+		return nil, fmt.Errorf("no process %q in this chat; see process_list", id)
+	}
+	return p, nil
+}
+
+// Output returns the caller's process info and its output: the last tail lines
+// when tail > 0, otherwise everything written since the previous poll. It
+// notes when output was dropped from the rolling buffer.
+func (m *ProcessManager) Output(ctx context.Context, id string, tail int) (ProcessInfo, string, error) {
+	p, err := m.get(ctx, id)
+	if err != nil {
+		return ProcessInfo{}, "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	buf := string(p.buf)
+	start := p.total - int64(len(p.buf)) // output offset of buf[0]
+	var out string
+	switch {
+	case tail > 0:
+		lines := strings.Split(strings.TrimSuffix(buf, "\n"), "\n")
+		if len(lines) > tail {
+			lines = lines[len(lines)-tail:]
+		}
+		out = strings.Join(lines, "\n")
+	case p.read < start:
+		out = fmt.Sprintf("[%d bytes not shown; see %s]\n", start-p.read, p.info.LogPath) + buf
+	default:
+		out = buf[p.read-start:]
+	}
+	p.read = p.total
+	return p.info, out, nil
+}
+
+// List returns the caller's processes, oldest first.
+func (m *ProcessManager) List(ctx context.Context) []ProcessInfo {
+	key := CallerFrom(ctx).SessionKey
+	m.mu.Lock()
+	var out []ProcessInfo
+	for _, p := range m.procs {
+		if info := p.snapshot(); info.SessionKey == key {
+			out = append(out, info)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// Kill stops the caller's process: SIGTERM to its process group, then SIGKILL
+// if it is still running after grace.
+func (m *ProcessManager) Kill(ctx context.Context, id string, grace time.Duration) (ProcessInfo, error) {
+	p, err := m.get(ctx, id)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	if !p.snapshot().Running() {
+		return p.snapshot(), nil
+	}
+	m.stop(p, grace)
+	return p.snapshot(), nil
+}
+
+func (m *ProcessManager) stop(p *managedProcess, grace time.Duration) {
+	p.mu.Lock()
+	p.info.Killed = true
+	p.mu.Unlock()
+	terminateProcessGroup(p.cmd)
+	select {
+	case <-p.done:
+	case <-time.After(grace):
+		killProcessGroup(p.cmd)
+		<-p.done
+	}
+}
+
+// KillSession stops every running process of a session in the background,
+// e.g. when it is reset, and returns how many it is stopping.
+func (m *ProcessManager) KillSession(sessionKey string) int {
+	m.mu.Lock()
+	var running []*managedProcess
+	for _, p := range m.procs {
+		if info := p.snapshot(); info.SessionKey == sessionKey && info.Running() {
+			running = append(running, p)
+		}
+	}
+	m.mu.Unlock()
+	for _, p := range running {
+		go m.stop(p, 5*time.Second)
+	}
+	return len(running)
+}
+
+// Close stops every running process.
+func (m *ProcessManager) Close() {
+	m.mu.Lock()
+	var running []*managedProcess
+	for _, p := range m.procs {
+		if p.snapshot().Running() {
+			running = append(running, p)
+		}
+	}
+	m.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, p := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.stop(p, 5*time.Second)
+		}()
+	}
+	wg.Wait()
+}
+
+// FormatProcesses renders processes for process_list.
+func FormatProcesses(procs []ProcessInfo, now time.Time) string {
+	if len(procs) == 0 {
+		return "No background processes in this chat."
+	}
+	var sb strings.Builder
+	for i, p := range procs {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s  %s  %s\n    log: %s", p.ID, processStatus(p, now), truncateCommand(p.Command), p.LogPath)
+	}
+	return sb.String()
+}
+
+func processStatus(p ProcessInfo, now time.Time) string {
+	switch {
+	case p.Running():
+		return "running for " + now.Sub(p.Started).Round(time.Second).String()
+	case p.Killed:
+		return fmt.Sprintf("killed after %s", p.Ended.Sub(p.Started).Round(time.Second))
+	default:
+		return fmt.Sprintf("exited %d after %s", p.ExitCode, p.Ended.Sub(p.Started).Round(time.Second))
+	}
+}
+
+func truncateCommand(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:79]) + "…"
+	}
+	return s
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
 )
@@ -39,6 +40,27 @@ func (r *Registry) Definitions() []provider.ToolDefinition {
 	return defs
 }
 
+// Names returns the registered tool names, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefinitionsFor returns the definitions of the named tools that are registered.
+func (r *Registry) DefinitionsFor(names []string) []provider.ToolDefinition {
+	defs := make([]provider.ToolDefinition, 0, len(names))
+	for _, name := range names {
+		if t, ok := r.tools[name]; ok {
+			defs = append(defs, t.Definition())
+		}
+	}
+	return defs
+}
+
 // Execute runs the named tool with the given JSON input.
 func (r *Registry) Execute(ctx context.Context, name string, input json.RawMessage) (string, error) {
 	t, ok := r.tools[name]
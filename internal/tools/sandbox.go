@@ -0,0 +1,64 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SandboxInitCommand is the hidden miniclaw subcommand that sets up the mounts
+// inside a fresh set of namespaces before running a sandboxed command.
+const SandboxInitCommand = "sandbox-init"
+
+// Sandbox confines exec commands: the workspace is writable, the rest of the
+// filesystem read-only, the network unreachable unless Network is set, and CPU
+// time, memory and process count are capped. It uses bubblewrap when installed
+// and Linux namespaces otherwise.
+type Sandbox struct {
+	Network    bool
+	CPUSeconds int
+	MemoryMB   int
+	MaxProcs   int // counts every process of the gateway's user
+}
+
+// NewSandbox creates a Sandbox, using defaults for limits that are 0.
+func NewSandbox(network bool, cpuSeconds, memoryMB, maxProcs int) *Sandbox {
+	if cpuSeconds <= 0 {
+		cpuSeconds = 60
+	}
+	if memoryMB <= 0 {
+		memoryMB = 2048
+	}
+	if maxProcs <= 0 {
+		maxProcs = 512
+	}
+	return &Sandbox{Network: network, CPUSeconds: cpuSeconds, MemoryMB: memoryMB, MaxProcs: maxProcs}
+}
+
+// Describe summarises the sandbox policy for the exec tool description.
+func (s *Sandbox) Describe() string {
+	if s == nil {
+		return ""
+	}
+	network := "no network access"
+	if s.Network {
+		network = "network access"
+	}
+	return fmt.Sprintf(" Commands run sandboxed (%s): the workspace is writable, the rest of the filesystem is read-only, /tmp is private, %s, limits of %ds CPU, %d MB memory and %d processes.",
+		s.backend(), network, s.CPUSeconds, s.MemoryMB, s.MaxProcs)
+}
+
+// shell returns the argv running script under the sandbox's rlimits. bash's
+// ulimit sets soft and hard limits, so the script cannot raise them again.
+func (s *Sandbox) shell(script string) []string {
+	limits := fmt.Sprintf("ulimit -t %d -v %d -u %d || exit 126\nexec bash -c \"$1\"", s.CPUSeconds, s.MemoryMB*1024, s.MaxProcs)
+	return []string{"bash", "-c", limits, "bash", script}
+}
+
+// sandboxInitArgs parses "<workspace> <dir> -- <argv...>".
+func sandboxInitArgs(args []string) (workspace, dir string, argv []string, err error) {
+	if len(args) < 4 || args[2] != "--" || !strings.HasPrefix(args[0], "/") {
+		return "", "", nil, fmt.Errorf("usage: miniclaw %s <workspace> <dir> -- <command...>", SandboxInitCommand)
+	}
+	return args[0], args[1], args[3:], nil
+}
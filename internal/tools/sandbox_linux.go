@@ -0,0 +1,197 @@
+// MIT License - Copyright (c) 2026 yosebyte
+
+//go:build linux
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func (s *Sandbox) backend() string {
+	if _, err := exec.LookPath("bwrap"); err == nil {
+		return "bubblewrap"
+	}
+	return "Linux namespaces"
+}
+
+// Command returns a command running script in the sandbox with dir as its
+// working directory and workspace mounted read-write.
+func (s *Sandbox) Command(ctx context.Context, script, dir, workspace string) (*exec.Cmd, error) {
+	if workspace == "" {
+		return nil, errors.New("sandbox: no workspace")
+	}
+	if bwrap, err := exec.LookPath("bwrap"); err == nil {
+		args := []string{"--die-with-parent", "--unshare-user", "--unshare-pid", "--unshare-ipc", "--unshare-uts"}
+		if !s.Network {
+			args = append(args, "--unshare-net")
+		}
+		args = append(args,
+			"--ro-bind", "/", "/",
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+			"--bind", workspace, workspace,
+			"--chdir", dir,
+			"--")
+		return exec.CommandContext(ctx, bwrap, append(args, s.shell(script)...)...), nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: %w", err)
+	}
+	args := append([]string{SandboxInitCommand, workspace, dir, "--"}, s.shell(script)...)
+	cmd := exec.CommandContext(ctx, self, args...)
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS)
+	if !s.Network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 flags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+		Pdeathsig:                  syscall.SIGKILL,
+	}
+	return cmd, nil
+}
+
+// SandboxInit runs as PID 1 of the namespaces created by Command: it makes
+// every mount except the workspace read-only, mounts a private /proc and /tmp,
+// and replaces itself with the command. It only returns on failure.
+func SandboxInit(args []string) error {
+	ws, dir, argv, err := sandboxInitArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	// A bind mount of the workspace onto itself keeps it writable when the
+	// mount containing it is made read-only.
+	if err := syscall.Mount(ws, ws, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind workspace: %w", err)
+	}
+	mounts, err := mountPoints()
+	if err != nil {
+		return err
+	}
+	for _, mp := range mounts {
+		if within(mp, ws) {
+			continue
+		}
+		if err := remountReadOnly(mp); err != nil && !ignorableMount(mp, err) {
+			return fmt.Errorf("remount %s read-only: %w", mp, err)
+		}
+	}
+	// A /proc for the new PID namespace; the host's stays if the kernel refuses.
+	syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	if !within(ws, "/tmp") {
+		if err := syscall.Mount("tmpfs", "/tmp", "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777"); err != nil {
+			return fmt.Errorf("mount /tmp: %w", err)
+		}
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, argv, os.Environ())
+}
+
+// mountPoints lists the mount points in /proc/self/mountinfo.
+func mountPoints() ([]string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		out = append(out, unescapeMount(fields[4]))
+	}
+	return out, sc.Err()
+}
+
+// unescapeMount decodes the octal escapes (\040 for space) mountinfo uses.
+func unescapeMount(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// statfs flags not defined by package syscall.
+const (
+	stNoSuid     = 0x2
+	stNoDev      = 0x4
+	stNoExec     = 0x8
+	stNoAtime    = 0x400
+	stNoDirAtime = 0x800
+	stRelAtime   = 0x1000
+)
+
+// remountReadOnly makes mp read-only, keeping its other flags: inside a user
+// namespace the kernel refuses remounts that would clear them.
+func remountReadOnly(mp string) error {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(mp, &st); err != nil {
+		return err
+	}
+	flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND | syscall.MS_RDONLY)
+	for _, f := range []struct{ st, ms uintptr }{
+		{stNoSuid, syscall.MS_NOSUID},
+		{stNoDev, syscall.MS_NODEV},
+		{stNoExec, syscall.MS_NOEXEC},
+		{stNoAtime, syscall.MS_NOATIME},
+		{stNoDirAtime, syscall.MS_NODIRATIME},
+		{stRelAtime, syscall.MS_RELATIME},
+	} {
+		if uintptr(st.Flags)&f.st != 0 {
+			flags |= f.ms
+		}
+	}
+	if uintptr(st.Flags)&(stNoAtime|stRelAtime) == 0 {
+		flags |= syscall.MS_STRICTATIME
+	}
+	return syscall.Mount("", mp, "", flags, "")
+}
+
+// ignorableMount reports whether a failed remount can be skipped: kernel
+// pseudo-filesystems that are replaced or not writable anyway, and mounts
+// hidden beneath others.
+func ignorableMount(mp string, err error) bool {
+	if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENOTDIR) {
+		return true
+	}
+	for _, root := range []string{"/proc", "/sys", "/dev"} {
+		if within(mp, root) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+// MIT License - Copyright (c) 2026 yosebyte
+
+//go:build !linux
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+var errNoSandbox = errors.New("the exec sandbox is only supported on Linux")
+
+func (s *Sandbox) backend() string { return "unsupported on this OS" }
+
+// Command is unavailable outside Linux.
+func (s *Sandbox) Command(ctx context.Context, script, dir, workspace string) (*exec.Cmd, error) {
+	return nil, errNoSandbox
+}
+
+// SandboxInit is unavailable outside Linux.
+func SandboxInit(args []string) error {
+	return errNoSandbox
+}
@@ -0,0 +1,81 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// SessionSearchQuery is the parsed input of session_search.
+type SessionSearchQuery struct {
+	Query string
+	From  time.Time // inclusive
+	To    time.Time // exclusive
+	Role  string
+	Limit int
+}
+
+// SessionSearchTool searches past conversations.
+type SessionSearchTool struct {
+	searchFunc func(ctx context.Context, q SessionSearchQuery) (string, error)
+	loc        *time.Location // dates are days in this zone
+}
+
+// NewSessionSearchTool creates a SessionSearchTool that reads dates in loc.
+func NewSessionSearchTool(loc *time.Location, searchFunc func(ctx context.Context, q SessionSearchQuery) (string, error)) SessionSearchTool {
+	return SessionSearchTool{searchFunc: searchFunc, loc: loc}
+}
+
+func (t SessionSearchTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "session_search",
+		Description: "Full-text search over all past conversations, including ones no longer in your context. Returns matching messages with session, timestamp and a snippet, best match first.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "query": {"type": "string",  "description": "Words to search for."},
+    "from":  {"type": "string",  "description": "Only messages on or after this date (YYYY-MM-DD)."},
+    "to":    {"type": "string",  "description": "Only messages on or before this date (YYYY-MM-DD)."},
+    "role":  {"type": "string",  "enum": ["user", "assistant"], "description": "Only messages from this role."},
+    "limit": {"type": "integer", "description": "Maximum results (default 10)."}
+  },
+  "required": ["query"]
+}`),
+	}
+}
+
+func (t SessionSearchTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Query string `json:"query"`
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Role  string `json:"role"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.Query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	q := SessionSearchQuery{Query: args.Query, Role: args.Role, Limit: args.Limit}
+	if args.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", args.From, t.loc)
+		if err != nil {
+			return "", fmt.Errorf("invalid from date %q (want YYYY-MM-DD)", args.From)
+		}
+		q.From = from
+	}
+	if args.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", args.To, t.loc)
+		if err != nil {
+			return "", fmt.Errorf("invalid to date %q (want YYYY-MM-DD)", args.To)
+		}
+		q.To = to.AddDate(0, 0, 1)
+	}
+	return t.searchFunc(ctx, q)
+}
@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/yosebyte/miniclaw/internal/provider"
@@ -15,17 +17,36 @@ import (
 // ExecTool runs shell commands.
 type ExecTool struct {
 	Timeout time.Duration
+	Sandbox *Sandbox   // nil runs commands unconfined
+	Shells  *ShellPool // when set, each chat session keeps one persistent shell
+	Policy  *CommandPolicy
 }
 
 func (e ExecTool) Definition() provider.ToolDefinition {
+	if e.Shells != nil {
+		return provider.ToolDefinition{
+			Name: "exec",
+			Description: "Execute a shell command and return combined stdout+stderr. Timeout: 60 seconds." +
+				" Commands run in a persistent shell for this chat: the working directory, exported variables and activated virtualenvs carry over to later calls." +
+				" Set reset to start a fresh shell in the workspace." + e.Sandbox.Describe() + e.Policy.Describe(),
+			InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "command": {"type": "string", "description": "Shell command to execute."},
+    "workdir": {"type": "string", "description": "Directory to cd into first (optional); the shell stays there."},
+    "reset":   {"type": "boolean", "description": "Restart the shell before running the command, dropping its directory and environment. May be used without a command."}
+  }
+}`),
+		}
+	}
 	return provider.ToolDefinition{
 		Name:        "exec",
-		Description: "Execute a shell command and return combined stdout+stderr. Timeout: 60 seconds.",
+		Description: "Execute a shell command and return combined stdout+stderr. Timeout: 60 seconds." + e.Sandbox.Describe() + e.Policy.Describe(),
 		InputSchema: json.RawMessage(`{
   "type": "object",
   "properties": {
     "command": {"type": "string", "description": "Shell command to execute."},
-    "workdir": {"type": "string", "description": "Working directory (optional)."}
+    "workdir": {"type": "string", "description": "Working directory (optional, defaults to the workspace)."}
   },
   "required": ["command"]
 }`),
@@ -36,6 +57,7 @@ func (e ExecTool) Execute(ctx context.Context, input json.RawMessage) (string, e
 	var args struct {
 		Command string `json:"command"`
 		Workdir string `json:"workdir"`
+		Reset   bool   `json:"reset"`
 	}
 	if err := json.Unmarshal(input, &args); err != nil {
 		return "", err
@@ -45,13 +67,30 @@ func (e ExecTool) Execute(ctx context.Context, input json.RawMessage) (string, e
 	if timeout == 0 {
 		timeout = 60 * time.Second
 	}
+	if err := e.Policy.Check(ctx, args.Command); err != nil {
+		return "", fmt.Errorf("exec: %w", err)
+	}
+	if e.Shells != nil {
+		return e.runPersistent(ctx, args.Command, args.Workdir, args.Reset, timeout)
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", args.Command)
+	workspace := CallerFrom(ctx).Workspace
+	dir := workspace
 	if args.Workdir != "" {
-		cmd.Dir = expandHome(args.Workdir)
+		dir = resolvePath(ctx, args.Workdir)
+	}
+	var cmd *exec.Cmd
+	if e.Sandbox != nil {
+		var err error
+		if cmd, err = e.Sandbox.Command(ctx, args.Command, dir, workspace); err != nil {
+			return "", fmt.Errorf("exec: %w", err)
+		}
+	} else {
+		cmd = exec.CommandContext(ctx, "bash", "-c", args.Command)
+		cmd.Dir = dir
 	}
 
 	var out bytes.Buffer
@@ -72,3 +111,46 @@ func (e ExecTool) Execute(ctx context.Context, input json.RawMessage) (string, e
 	}
 	return output, nil
 }
+
+// runPersistent runs command in the caller's persistent shell.
+func (e ExecTool) runPersistent(ctx context.Context, command, workdir string, reset bool, timeout time.Duration) (string, error) {
+	caller := CallerFrom(ctx)
+	key := caller.SessionKey
+	if key == "" {
+		key = "default"
+	}
+	note := ""
+	if reset {
+		e.Shells.Reset(key)
+		note = "[shell reset]\n"
+	}
+	if workdir != "" {
+		command = "cd " + shellQuote(resolvePath(ctx, workdir)) + " && " + command
+	}
+	if strings.TrimSpace(command) == "" {
+		if reset {
+			return "[shell reset]", nil
+		}
+		return "", fmt.Errorf("exec: command is required")
+	}
+
+	output, code, err := e.Shells.Run(ctx, key, caller.Workspace, command, timeout)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return note + output + "\n[command timed out; the shell was restarted, so its directory and environment are reset]", nil
+	case errors.Is(err, errShellExited):
+		return note + output + "\n[the shell exited; the next command starts a fresh one]", nil
+	case err != nil:
+		return "", fmt.Errorf("exec: %w", err)
+	case code != 0:
+		return fmt.Sprintf("%sexit code %d:\n%s", note, code, output), nil
+	case output == "":
+		return note + "(no output)", nil
+	}
+	return note + output, nil
+}
+
+// shellQuote quotes s for bash.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,499 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// shellCommands is what splitShell finds in a command line: every simple
+// command and every pipeline, as normalised text ("curl -s x", "curl -s x | sh").
+type shellCommands struct {
+	commands  []string
+	pipelines []string
+}
+
+// maxShellDepth bounds nested substitutions and `sh -c` strings.
+const maxShellDepth = 8
+
+var reAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// splitShell parses src well enough to find every command it runs: it splits
+// pipelines and lists, and descends into $(...) (also within $((...))), `...`,
+// <(...), subshells, `bash -c` strings, eval, and heredocs and here-strings fed
+// to a shell. Quotes are removed, other heredoc bodies skipped and leading
+// variable assignments and wrappers such as nohup or env dropped.
+func splitShell(src string) (shellCommands, error) {
+	var out shellCommands
+	err := parseShell(src, 0, &out)
+	return out, err
+}
+
+type shellParser struct {
+	src      string
+	pos      int
+	depth    int
+	out      *shellCommands
+	word     strings.Builder
+	inWord   bool
+	words    []string
+	pipeline []string
+	heredocs []heredoc
+	piped    int      // heredocs declared before the current pipeline
+	herestr  []string // here-strings of the current pipeline
+}
+
+// heredoc is a pending heredoc; run is set when its body is a script for a
+// shell, which then has to be checked like the rest of the command.
+type heredoc struct {
+	delim string
+	run   bool
+}
+
+func parseShell(src string, depth int, out *shellCommands) error {
+	if depth > maxShellDepth {
+		return fmt.Errorf("commands nested too deeply")
+	}
+	p := &shellParser{src: src, depth: depth, out: out}
+	return p.parse()
+}
+
+func (p *shellParser) parse() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.endWord()
+			p.pos++
+		case c == '\n':
+			p.endWord()
+			p.endPipeline()
+			p.pos++
+			if err := p.readHeredocs(); err != nil {
+				return err
+			}
+		case c == '#' && !p.inWord:
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\\':
+			if p.pos+1 < len(p.src) {
+				if p.src[p.pos+1] != '\n' { // backslash-newline continues the line
+					p.add(string(p.src[p.pos+1]))
+				}
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+		case c == '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return fmt.Errorf("unterminated ' quote")
+			}
+			p.add(p.src[p.pos+1 : p.pos+1+end])
+			p.pos += end + 2
+		case c == '"':
+			if err := p.doubleQuoted(); err != nil {
+				return err
+			}
+		case c == '`' || c == '$' && p.peek(1) == '(' || (c == '<' || c == '>') && p.peek(1) == '(' && !p.inWord:
+			if err := p.substitution(); err != nil {
+				return err
+			}
+		case c == '&' && (p.endsWithRedirect() || p.peek(1) == '>'):
+			p.add("&") // 2>&1, &>file
+			p.pos++
+		case c == '|':
+			p.endWord()
+			if p.peek(1) == '|' {
+				p.endPipeline()
+				p.pos += 2
+			} else {
+				p.endCommand()
+				p.pos++
+				if p.peek(0) == '&' {
+					p.pos++
+				}
+			}
+		case c == ';' || c == '&' || c == '(' || c == ')':
+			p.endWord()
+			p.endPipeline()
+			p.pos++
+		default:
+			p.add(string(c))
+			p.pos++
+		}
+	}
+	p.endWord()
+	p.endPipeline()
+	return p.readHeredocs() // unterminated heredocs run to the end
+}
+
+func (p *shellParser) peek(n int) byte {
+	if p.pos+n < len(p.src) {
+		return p.src[p.pos+n]
+	}
+	return 0
+}
+
+func (p *shellParser) add(s string) {
+	p.word.WriteString(s)
+	p.inWord = true
+}
+
+func (p *shellParser) endsWithRedirect() bool {
+	w := p.word.String()
+	return p.inWord && (strings.HasSuffix(w, ">") || strings.HasSuffix(w, "<"))
+}
+
+func (p *shellParser) endWord() {
+	if !p.inWord {
+		return
+	}
+	w := p.word.String()
+	p.word.Reset()
+	p.inWord = false
+	// Remember heredoc delimiters so their bodies are not read as commands.
+	if n := len(p.words); n > 0 && (p.words[n-1] == "<<" || p.words[n-1] == "<<-") {
+		p.heredocs = append(p.heredocs, heredoc{delim: w})
+	} else if d, ok := strings.CutPrefix(w, "<<"); ok && d != "" && !strings.HasPrefix(d, "<") {
+		p.heredocs = append(p.heredocs, heredoc{delim: strings.TrimPrefix(d, "-")})
+	}
+	p.words = append(p.words, w)
+}
+
+// readHeredocs consumes the bodies of the pending heredocs, which follow the
+// line that declared them, and parses those fed to a shell.
+func (p *shellParser) readHeredocs() error {
+	var body strings.Builder
+	for len(p.heredocs) > 0 && p.pos < len(p.src) {
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		line := p.src[p.pos:]
+		if end >= 0 {
+			line = line[:end]
+			p.pos += end + 1
+		} else {
+			p.pos = len(p.src)
+		}
+		if strings.TrimLeft(line, "\t") != p.heredocs[0].delim {
+			body.WriteString(line + "\n")
+			continue
+		}
+		if err := p.heredocBody(body.String()); err != nil {
+			return err
+		}
+		body.Reset()
+	}
+	if len(p.heredocs) > 0 { // the script ended inside a heredoc
+		if err := p.heredocBody(body.String()); err != nil {
+			return err
+		}
+		p.heredocs = nil
+	}
+	p.piped = 0
+	return nil
+}
+
+func (p *shellParser) heredocBody(body string) error {
+	h := p.heredocs[0]
+	p.heredocs = p.heredocs[1:]
+	if !h.run {
+		return nil
+	}
+	return parseShell(body, p.depth+1, p.out)
+}
+
+func (p *shellParser) doubleQuoted() error {
+	p.pos++ // opening quote
+	p.inWord = true
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return nil
+		case c == '\\' && p.pos+1 < len(p.src) && strings.IndexByte("\"\\$`\n", p.src[p.pos+1]) >= 0:
+			if p.src[p.pos+1] != '\n' {
+				p.add(string(p.src[p.pos+1]))
+			}
+			p.pos += 2
+		case c == '`' || c == '$' && p.peek(1) == '(':
+			if err := p.substitution(); err != nil {
+				return err
+			}
+		default:
+			p.add(string(c))
+			p.pos++
+		}
+	}
+	return fmt.Errorf(`unterminated " quote`)
+}
+
+// substitution handles $(...), $((...)), <(...), >(...) and `...` at pos:
+// the text stays in the current word and the inner commands are parsed too.
+func (p *shellParser) substitution() error {
+	start := p.pos
+	if p.src[p.pos] == '`' {
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != '`' {
+			if p.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			return fmt.Errorf("unterminated ` substitution")
+		}
+		p.add(p.src[start : end+1])
+		p.pos = end + 1
+		return parseShell(strings.ReplaceAll(p.src[start+1:end], "\\`", "`"), p.depth+1, p.out)
+	}
+	arith := p.src[p.pos] == '$' && p.peek(2) == '('
+	end, err := matchParen(p.src, p.pos+1)
+	if err != nil {
+		return err
+	}
+	p.add(p.src[start : end+1])
+	p.pos = end + 1
+	if arith {
+		return arithSubstitutions(p.src[start+3:end-1], p.depth+1, p.out)
+	}
+	return parseShell(p.src[start+2:end], p.depth+1, p.out)
+}
+
+// arithSubstitutions parses the command substitutions inside an arithmetic
+// expansion, which bash runs before evaluating it.
+func arithSubstitutions(expr string, depth int, out *shellCommands) error {
+	if depth > maxShellDepth {
+		return fmt.Errorf("commands nested too deeply")
+	}
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\':
+			i++
+		case expr[i] == '$' && i+1 < len(expr) && expr[i+1] == '(':
+			end, err := matchParen(expr, i+1)
+			if err != nil {
+				return err
+			}
+			inner := expr[i+2 : end]
+			if len(inner) >= 2 && inner[0] == '(' && inner[len(inner)-1] == ')' {
+				err = arithSubstitutions(inner[1:len(inner)-1], depth+1, out)
+			} else {
+				err = parseShell(inner, depth+1, out)
+			}
+			if err != nil {
+				return err
+			}
+			i = end
+		case expr[i] == '`':
+			end := strings.IndexByte(expr[i+1:], '`')
+			if end < 0 {
+				return fmt.Errorf("unterminated ` substitution")
+			}
+			if err := parseShell(expr[i+1:i+1+end], depth+1, out); err != nil {
+				return err
+			}
+			i += end + 1
+		}
+	}
+	return nil
+}
+
+// matchParen returns the index of the ")" closing the "(" at open, skipping
+// quoted text and nested parentheses.
+func matchParen(src string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated ' quote")
+			}
+			i += end + 1
+		case '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated ( in substitution")
+}
+
+// shellKeywords precede a command without being one.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "do": true,
+	"while": true, "until": true, "!": true, "{": true, "fi": true, "done": true, "esac": true, "}": true,
+}
+
+// stdinShells run the script they read from stdin.
+var stdinShells = map[string]bool{
+	"bash": true, "sh": true, "zsh": true, "dash": true, "ksh": true, "eval": true, "source": true, ".": true,
+}
+
+// shellWrapper describes a program that runs the command following its own
+// options: argOpts take a separate argument, and positional arguments such as
+// timeout's duration come before the command.
+type shellWrapper struct {
+	argOpts    []string
+	positional int
+}
+
+var shellWrappers = map[string]shellWrapper{
+	"nohup": {}, "time": {}, "command": {}, "builtin": {}, "setsid": {},
+	"exec":    {argOpts: []string{"-a"}},
+	"env":     {argOpts: []string{"-u", "--unset", "-C", "--chdir"}},
+	"timeout": {argOpts: []string{"-s", "--signal", "-k", "--kill-after"}, positional: 1},
+	"nice":    {argOpts: []string{"-n", "--adjustment"}},
+	"stdbuf":  {argOpts: []string{"-i", "-o", "-e", "--input", "--output", "--error"}},
+	"ionice":  {argOpts: []string{"-c", "--class", "-n", "--classdata", "-p", "--pid", "-P", "--pgid", "-u", "--uid"}},
+	"xargs": {argOpts: []string{"-a", "--arg-file", "-d", "--delimiter", "-E", "-I", "-L", "--max-lines",
+		"-n", "--max-args", "-P", "--max-procs", "-s", "--max-chars", "--process-slot-var"}},
+}
+
+// unwrap drops a wrapper, its options and its positional arguments, leaving
+// the command it runs. env's -S string is a command line of its own.
+func (p *shellParser) unwrap(spec shellWrapper, words []string) []string {
+	name := filepath.Base(words[0])
+	words = words[1:]
+	for len(words) > 0 {
+		w := words[0]
+		if w == "--" {
+			words = words[1:]
+			break
+		}
+		if name == "env" && reAssignment.MatchString(w) {
+			words = words[1:]
+			continue
+		}
+		if !strings.HasPrefix(w, "-") {
+			break
+		}
+		words = words[1:]
+		if name == "env" && (w == "-S" || w == "--split-string") && len(words) > 0 {
+			parseShell(words[0], p.depth+1, p.out)
+			words = words[1:]
+		} else if s, ok := strings.CutPrefix(w, "--split-string="); name == "env" && ok {
+			parseShell(s, p.depth+1, p.out)
+		} else if s, ok := strings.CutPrefix(w, "-S"); name == "env" && ok {
+			parseShell(s, p.depth+1, p.out)
+		} else if slices.Contains(spec.argOpts, w) && len(words) > 0 {
+			words = words[1:]
+		}
+	}
+	return words[min(spec.positional, len(words)):]
+}
+
+// findCommands returns the commands run by find's -exec, -execdir, -ok and
+// -okdir actions, which end at ";" or "+".
+func findCommands(words []string) [][]string {
+	var cmds [][]string
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "-exec", "-execdir", "-ok", "-okdir":
+			j := i + 1
+			for j < len(words) && words[j] != ";" && words[j] != "+" {
+				j++
+			}
+			if j > i+1 {
+				cmds = append(cmds, words[i+1:j])
+			}
+			i = j
+		}
+	}
+	return cmds
+}
+
+// endCommand normalises the words read so far into a simple command.
+func (p *shellParser) endCommand() {
+	words := p.words
+	p.words = nil
+	for len(words) > 0 {
+		w := words[0]
+		spec, wrapper := shellWrappers[filepath.Base(w)]
+		switch {
+		case shellKeywords[w] || reAssignment.MatchString(w):
+			words = words[1:]
+		case wrapper:
+			words = p.unwrap(spec, words)
+		default:
+			goto done
+		}
+	}
+done:
+	// Here-strings are kept until the pipeline ends, in case it runs a shell.
+	for i, w := range words {
+		if w == "<<<" && i+1 < len(words) {
+			p.herestr = append(p.herestr, words[i+1])
+		} else if s, ok := strings.CutPrefix(w, "<<<"); ok && s != "" {
+			p.herestr = append(p.herestr, s)
+		}
+	}
+	if len(words) == 0 {
+		return
+	}
+	switch words[0] {
+	case "for", "case", "select", "function", "in":
+		return // loop and case headers; their substitutions were parsed already
+	}
+	text := strings.Join(words, " ")
+	p.out.commands = append(p.out.commands, text)
+	p.pipeline = append(p.pipeline, text)
+
+	// Strings run by a nested shell or eval are commands too. Errors here
+	// are ignored: the outer command is still checked as written.
+	switch filepath.Base(words[0]) {
+	case "bash", "sh", "zsh", "dash", "ksh":
+		for i := 1; i+1 < len(words); i++ {
+			if strings.HasPrefix(words[i], "-") && strings.Contains(words[i], "c") && !strings.HasPrefix(words[i], "--") {
+				parseShell(words[i+1], p.depth+1, p.out)
+				break
+			}
+		}
+	case "eval":
+		parseShell(strings.Join(words[1:], " "), p.depth+1, p.out)
+	case "find":
+		if p.depth < maxShellDepth {
+			for _, cmd := range findCommands(words) {
+				sub := &shellParser{depth: p.depth + 1, out: p.out, words: cmd}
+				sub.endCommand()
+			}
+		}
+	}
+	// So are heredocs and here-strings fed to one, including those of
+	// earlier commands piped into it.
+	if stdinShells[filepath.Base(words[0])] {
+		for i := p.piped; i < len(p.heredocs); i++ {
+			p.heredocs[i].run = true
+		}
+		for _, s := range p.herestr {
+			parseShell(s, p.depth+1, p.out)
+		}
+		p.herestr = nil
+	}
+}
+
+func (p *shellParser) endPipeline() {
+	p.endCommand()
+	if len(p.pipeline) > 0 {
+		p.out.pipelines = append(p.out.pipelines, strings.Join(p.pipeline, " | "))
+	}
+	p.pipeline = nil
+	p.piped = len(p.heredocs)
+	p.herestr = nil
+}
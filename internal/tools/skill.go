@@ -0,0 +1,47 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// LoadSkillTool returns the full instructions of a workspace skill.
+type LoadSkillTool struct {
+	loadFunc func(ctx context.Context, name string) (string, error)
+}
+
+// NewLoadSkillTool creates a LoadSkillTool.
+func NewLoadSkillTool(loadFunc func(ctx context.Context, name string) (string, error)) LoadSkillTool {
+	return LoadSkillTool{loadFunc: loadFunc}
+}
+
+func (t LoadSkillTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "load_skill",
+		Description: "Load the full instructions and bundled scripts of a skill listed under 'Skills' in the system prompt. Call this before following a skill's procedure.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "description": "Skill name as listed in the system prompt."}
+  },
+  "required": ["name"]
+}`),
+	}
+}
+
+func (t LoadSkillTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	if args.Name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	return t.loadFunc(ctx, args.Name)
+}
@@ -0,0 +1,119 @@
+// MIT License - Copyright (c) 2026 yosebyte
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yosebyte/miniclaw/internal/provider"
+)
+
+// TodoItem is one entry of a session's task list.
+type TodoItem struct {
+	Content string `json:"content"`
+	Status  string `json:"status"` // "pending", "in_progress" or "completed"
+}
+
+// FormatTodos renders a task list as a checklist with a progress header.
+func FormatTodos(items []TodoItem) string {
+	if len(items) == 0 {
+		return "(no tasks)"
+	}
+	done := 0
+	var sb strings.Builder
+	for _, it := range items {
+		mark := "☐"
+		switch it.Status {
+		case "completed":
+			mark = "☑"
+			done++
+		case "in_progress":
+			mark = "▶"
+		}
+		fmt.Fprintf(&sb, "\n%s %s", mark, it.Content)
+	}
+	return fmt.Sprintf("Progress: %d/%d", done, len(items)) + sb.String()
+}
+
+// --- todo_write ---
+
+// TodoWriteTool replaces the current session's task list.
+type TodoWriteTool struct {
+	setFunc func(ctx context.Context, items []TodoItem)
+}
+
+// NewTodoWriteTool creates a TodoWriteTool.
+func NewTodoWriteTool(setFunc func(ctx context.Context, items []TodoItem)) TodoWriteTool {
+	return TodoWriteTool{setFunc: setFunc}
+}
+
+func (t TodoWriteTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "todo_write",
+		Description: "Create or update the task list for multi-step work. Send the full list every time; mark exactly one item in_progress while working on it and mark items completed as soon as they are done. The user sees the list as live progress.",
+		InputSchema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "todos": {
+      "type": "array",
+      "description": "The complete task list, in order.",
+      "items": {
+        "type": "object",
+        "properties": {
+          "content": {"type": "string", "description": "Short imperative description of the step."},
+          "status":  {"type": "string", "enum": ["pending", "in_progress", "completed"]}
+        },
+        "required": ["content", "status"]
+      }
+    }
+  },
+  "required": ["todos"]
+}`),
+	}
+}
+
+func (t TodoWriteTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	var args struct {
+		Todos []TodoItem `json:"todos"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return "", err
+	}
+	for i, it := range args.Todos {
+		switch it.Status {
+		case "pending", "in_progress", "completed":
+		case "":
+			args.Todos[i].Status = "pending"
+		default:
+			return "", fmt.Errorf("todo %d: invalid status %q", i+1, it.Status)
+		}
+	}
+	t.setFunc(ctx, args.Todos)
+	return FormatTodos(args.Todos), nil
+}
+
+// --- todo_read ---
+
+// TodoReadTool returns the current session's task list.
+type TodoReadTool struct {
+	getFunc func(ctx context.Context) []TodoItem
+}
+
+// NewTodoReadTool creates a TodoReadTool.
+func NewTodoReadTool(getFunc func(ctx context.Context) []TodoItem) TodoReadTool {
+	return TodoReadTool{getFunc: getFunc}
+}
+
+func (t TodoReadTool) Definition() provider.ToolDefinition {
+	return provider.ToolDefinition{
+		Name:        "todo_read",
+		Description: "Read the current task list for this conversation.",
+		InputSchema: json.RawMessage(`{"type": "object", "properties": {}}`),
+	}
+}
+
+func (t TodoReadTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	return FormatTodos(t.getFunc(ctx)), nil
+}